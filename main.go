@@ -11,7 +11,6 @@ import (
 
 	"github.com/Ayobami0/cli-chat/pb"
 	"github.com/Ayobami0/cli-chat/ui"
-	tea "github.com/charmbracelet/bubbletea"
 )
 
 const (
@@ -79,6 +78,17 @@ func main() {
 
 	client := pb.NewChatServiceClient(conn)
 
+	cfgPath, err := ui.DefaultConfigPath()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	cfg, err := ui.LoadConfig(cfgPath)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
 	switch os.Args[1] {
 	case "login":
 		loginCmd.Parse(os.Args[2:])
@@ -90,7 +100,7 @@ func main() {
 			fmt.Printf("cli-chat: invalid argument passed to <username>.\n\n%s\n", LOGIN_USAGE)
 			return
 		}
-		if _, err := tea.NewProgram(ui.NewLoginModel(username, client)).Run(); err != nil {
+		if err := ui.Run(ui.NewLoginModel(username, client, cfg)); err != nil {
 			fmt.Printf("could not start program: %s\n", err)
 		}
 	case "create":
@@ -99,7 +109,7 @@ func main() {
 			fmt.Printf("Create command.\n\n%s\n\nArguments:\n\t-h, --help: show help\n", CREATE_USAGE)
 			return
 		}
-		if _, err := tea.NewProgram(ui.NewCreateModel(client)).Run(); err != nil {
+		if err := ui.Run(ui.NewCreateModel(client, cfg)); err != nil {
 			fmt.Printf("could not start program: %s\n", err)
 		}
 	case "-h", "--help":
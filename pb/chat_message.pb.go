@@ -70,6 +70,60 @@ func (ChatType) EnumDescriptor() ([]byte, []int) {
 	return file_chat_message_proto_rawDescGZIP(), []int{0}
 }
 
+// The creator of a group is its owner. Owners and admins moderate the group;
+// only owners change who is an admin.
+type GroupRole int32
+
+const (
+	GroupRole_GROUP_ROLE_UNSPECIFIED GroupRole = 0
+	GroupRole_GROUP_ROLE_MEMBER      GroupRole = 1
+	GroupRole_GROUP_ROLE_ADMIN       GroupRole = 2
+	GroupRole_GROUP_ROLE_OWNER       GroupRole = 3
+)
+
+// Enum value maps for GroupRole.
+var (
+	GroupRole_name = map[int32]string{
+		0: "GROUP_ROLE_UNSPECIFIED",
+		1: "GROUP_ROLE_MEMBER",
+		2: "GROUP_ROLE_ADMIN",
+		3: "GROUP_ROLE_OWNER",
+	}
+	GroupRole_value = map[string]int32{
+		"GROUP_ROLE_UNSPECIFIED": 0,
+		"GROUP_ROLE_MEMBER":      1,
+		"GROUP_ROLE_ADMIN":       2,
+		"GROUP_ROLE_OWNER":       3,
+	}
+)
+
+func (x GroupRole) Enum() *GroupRole {
+	p := new(GroupRole)
+	*p = x
+	return p
+}
+
+func (x GroupRole) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GroupRole) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_message_proto_enumTypes[1].Descriptor()
+}
+
+func (GroupRole) Type() protoreflect.EnumType {
+	return &file_chat_message_proto_enumTypes[1]
+}
+
+func (x GroupRole) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GroupRole.Descriptor instead.
+func (GroupRole) EnumDescriptor() ([]byte, []int) {
+	return file_chat_message_proto_rawDescGZIP(), []int{1}
+}
+
 type DirectChatAction_Action int32
 
 const (
@@ -103,11 +157,11 @@ func (x DirectChatAction_Action) String() string {
 }
 
 func (DirectChatAction_Action) Descriptor() protoreflect.EnumDescriptor {
-	return file_chat_message_proto_enumTypes[1].Descriptor()
+	return file_chat_message_proto_enumTypes[2].Descriptor()
 }
 
 func (DirectChatAction_Action) Type() protoreflect.EnumType {
-	return &file_chat_message_proto_enumTypes[1]
+	return &file_chat_message_proto_enumTypes[2]
 }
 
 func (x DirectChatAction_Action) Number() protoreflect.EnumNumber {
@@ -119,6 +173,67 @@ func (DirectChatAction_Action) EnumDescriptor() ([]byte, []int) {
 	return file_chat_message_proto_rawDescGZIP(), []int{3, 0}
 }
 
+type GroupChatAction_Action int32
+
+const (
+	GroupChatAction_ACTION_UNSPECIFIED    GroupChatAction_Action = 0
+	GroupChatAction_ACTION_KICK           GroupChatAction_Action = 1
+	GroupChatAction_ACTION_BAN            GroupChatAction_Action = 2
+	GroupChatAction_ACTION_RENAME         GroupChatAction_Action = 3
+	GroupChatAction_ACTION_ROTATE_PASSKEY GroupChatAction_Action = 4
+	GroupChatAction_ACTION_DELETE_MESSAGE GroupChatAction_Action = 5
+	GroupChatAction_ACTION_SET_ROLE       GroupChatAction_Action = 6
+)
+
+// Enum value maps for GroupChatAction_Action.
+var (
+	GroupChatAction_Action_name = map[int32]string{
+		0: "ACTION_UNSPECIFIED",
+		1: "ACTION_KICK",
+		2: "ACTION_BAN",
+		3: "ACTION_RENAME",
+		4: "ACTION_ROTATE_PASSKEY",
+		5: "ACTION_DELETE_MESSAGE",
+		6: "ACTION_SET_ROLE",
+	}
+	GroupChatAction_Action_value = map[string]int32{
+		"ACTION_UNSPECIFIED":    0,
+		"ACTION_KICK":           1,
+		"ACTION_BAN":            2,
+		"ACTION_RENAME":         3,
+		"ACTION_ROTATE_PASSKEY": 4,
+		"ACTION_DELETE_MESSAGE": 5,
+		"ACTION_SET_ROLE":       6,
+	}
+)
+
+func (x GroupChatAction_Action) Enum() *GroupChatAction_Action {
+	p := new(GroupChatAction_Action)
+	*p = x
+	return p
+}
+
+func (x GroupChatAction_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GroupChatAction_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_chat_message_proto_enumTypes[3].Descriptor()
+}
+
+func (GroupChatAction_Action) Type() protoreflect.EnumType {
+	return &file_chat_message_proto_enumTypes[3]
+}
+
+func (x GroupChatAction_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GroupChatAction_Action.Descriptor instead.
+func (GroupChatAction_Action) EnumDescriptor() ([]byte, []int) {
+	return file_chat_message_proto_rawDescGZIP(), []int{5, 0}
+}
+
 type JoinDirectChatRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -386,6 +501,158 @@ func (x *GroupChatRequest) GetGroupPasskey() string {
 	return ""
 }
 
+// An action of an owner or admin on a group. The server sends the group a
+// MESSAGE_TYPE_NOTIFICATION message describing each action it carries out.
+type GroupChatAction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ChatId    string                 `protobuf:"bytes,1,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	Action    GroupChatAction_Action `protobuf:"varint,2,opt,name=action,proto3,enum=chat.GroupChatAction_Action" json:"action,omitempty"`
+	Member    *User                  `protobuf:"bytes,3,opt,name=member,proto3,oneof" json:"member,omitempty"`
+	Name      *string                `protobuf:"bytes,4,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Passkey   *string                `protobuf:"bytes,5,opt,name=passkey,proto3,oneof" json:"passkey,omitempty"`
+	MessageId *string                `protobuf:"bytes,6,opt,name=message_id,json=messageId,proto3,oneof" json:"message_id,omitempty"`
+	Role      GroupRole              `protobuf:"varint,7,opt,name=role,proto3,enum=chat.GroupRole" json:"role,omitempty"`
+}
+
+func (x *GroupChatAction) Reset() {
+	*x = GroupChatAction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chat_message_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GroupChatAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupChatAction) ProtoMessage() {}
+
+func (x *GroupChatAction) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_message_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupChatAction.ProtoReflect.Descriptor instead.
+func (*GroupChatAction) Descriptor() ([]byte, []int) {
+	return file_chat_message_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GroupChatAction) GetChatId() string {
+	if x != nil {
+		return x.ChatId
+	}
+	return ""
+}
+
+func (x *GroupChatAction) GetAction() GroupChatAction_Action {
+	if x != nil {
+		return x.Action
+	}
+	return GroupChatAction_ACTION_UNSPECIFIED
+}
+
+func (x *GroupChatAction) GetMember() *User {
+	if x != nil {
+		return x.Member
+	}
+	return nil
+}
+
+func (x *GroupChatAction) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *GroupChatAction) GetPasskey() string {
+	if x != nil && x.Passkey != nil {
+		return *x.Passkey
+	}
+	return ""
+}
+
+func (x *GroupChatAction) GetMessageId() string {
+	if x != nil && x.MessageId != nil {
+		return *x.MessageId
+	}
+	return ""
+}
+
+func (x *GroupChatAction) GetRole() GroupRole {
+	if x != nil {
+		return x.Role
+	}
+	return GroupRole_GROUP_ROLE_UNSPECIFIED
+}
+
+type MemberRole struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User *User     `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Role GroupRole `protobuf:"varint,2,opt,name=role,proto3,enum=chat.GroupRole" json:"role,omitempty"`
+}
+
+func (x *MemberRole) Reset() {
+	*x = MemberRole{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_chat_message_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MemberRole) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemberRole) ProtoMessage() {}
+
+func (x *MemberRole) ProtoReflect() protoreflect.Message {
+	mi := &file_chat_message_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemberRole.ProtoReflect.Descriptor instead.
+func (*MemberRole) Descriptor() ([]byte, []int) {
+	return file_chat_message_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MemberRole) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *MemberRole) GetRole() GroupRole {
+	if x != nil {
+		return x.Role
+	}
+	return GroupRole_GROUP_ROLE_UNSPECIFIED
+}
+
 type ChatRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -398,7 +665,7 @@ type ChatRequest struct {
 func (x *ChatRequest) Reset() {
 	*x = ChatRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_chat_message_proto_msgTypes[5]
+		mi := &file_chat_message_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -411,7 +678,7 @@ func (x *ChatRequest) String() string {
 func (*ChatRequest) ProtoMessage() {}
 
 func (x *ChatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_message_proto_msgTypes[5]
+	mi := &file_chat_message_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -424,7 +691,7 @@ func (x *ChatRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChatRequest.ProtoReflect.Descriptor instead.
 func (*ChatRequest) Descriptor() ([]byte, []int) {
-	return file_chat_message_proto_rawDescGZIP(), []int{5}
+	return file_chat_message_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ChatRequest) GetId() string {
@@ -446,18 +713,20 @@ type ChatResponse struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Messages  []*Message             `protobuf:"bytes,2,rep,name=messages,proto3" json:"messages,omitempty"`
-	Members   []*User                `protobuf:"bytes,3,rep,name=members,proto3" json:"members,omitempty"`
-	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Type      ChatType               `protobuf:"varint,5,opt,name=type,proto3,enum=chat.ChatType" json:"type,omitempty"`
-	Name      *string                `protobuf:"bytes,6,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Messages    []*Message             `protobuf:"bytes,2,rep,name=messages,proto3" json:"messages,omitempty"`
+	Members     []*User                `protobuf:"bytes,3,rep,name=members,proto3" json:"members,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Type        ChatType               `protobuf:"varint,5,opt,name=type,proto3,enum=chat.ChatType" json:"type,omitempty"`
+	Name        *string                `protobuf:"bytes,6,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	ReadMarkers []*ReadMarker          `protobuf:"bytes,7,rep,name=read_markers,json=readMarkers,proto3" json:"read_markers,omitempty"`
+	Roles       []*MemberRole          `protobuf:"bytes,8,rep,name=roles,proto3" json:"roles,omitempty"`
 }
 
 func (x *ChatResponse) Reset() {
 	*x = ChatResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_chat_message_proto_msgTypes[6]
+		mi := &file_chat_message_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -470,7 +739,7 @@ func (x *ChatResponse) String() string {
 func (*ChatResponse) ProtoMessage() {}
 
 func (x *ChatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_message_proto_msgTypes[6]
+	mi := &file_chat_message_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -483,7 +752,7 @@ func (x *ChatResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChatResponse.ProtoReflect.Descriptor instead.
 func (*ChatResponse) Descriptor() ([]byte, []int) {
-	return file_chat_message_proto_rawDescGZIP(), []int{6}
+	return file_chat_message_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ChatResponse) GetId() string {
@@ -528,6 +797,20 @@ func (x *ChatResponse) GetName() string {
 	return ""
 }
 
+func (x *ChatResponse) GetReadMarkers() []*ReadMarker {
+	if x != nil {
+		return x.ReadMarkers
+	}
+	return nil
+}
+
+func (x *ChatResponse) GetRoles() []*MemberRole {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
 type ChatsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -539,7 +822,7 @@ type ChatsResponse struct {
 func (x *ChatsResponse) Reset() {
 	*x = ChatsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_chat_message_proto_msgTypes[7]
+		mi := &file_chat_message_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -552,7 +835,7 @@ func (x *ChatsResponse) String() string {
 func (*ChatsResponse) ProtoMessage() {}
 
 func (x *ChatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_chat_message_proto_msgTypes[7]
+	mi := &file_chat_message_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -565,7 +848,7 @@ func (x *ChatsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ChatsResponse.ProtoReflect.Descriptor instead.
 func (*ChatsResponse) Descriptor() ([]byte, []int) {
-	return file_chat_message_proto_rawDescGZIP(), []int{7}
+	return file_chat_message_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ChatsResponse) GetChats() []*ChatResponse {
@@ -617,38 +900,86 @@ var file_chat_message_proto_rawDesc = []byte{
 	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x67, 0x72, 0x6f,
 	0x75, 0x70, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f,
 	0x70, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x67,
-	0x72, 0x6f, 0x75, 0x70, 0x50, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x22, 0x41, 0x0a, 0x0b, 0x43,
-	0x68, 0x61, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x22, 0x0a, 0x04, 0x63, 0x68,
-	0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e,
-	0x43, 0x68, 0x61, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x63, 0x68, 0x61, 0x74, 0x22, 0xf0,
-	0x01, 0x0a, 0x0c, 0x43, 0x68, 0x61, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12,
-	0x29, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x0d, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x52, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x07, 0x6d, 0x65,
-	0x6d, 0x62, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x63, 0x68,
-	0x61, 0x74, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73,
-	0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
-	0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x22, 0x0a, 0x04, 0x74,
-	0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x63, 0x68, 0x61, 0x74,
-	0x2e, 0x43, 0x68, 0x61, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12,
-	0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52,
-	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d,
-	0x65, 0x22, 0x39, 0x0a, 0x0d, 0x43, 0x68, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x28, 0x0a, 0x05, 0x63, 0x68, 0x61, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x12, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x43, 0x68, 0x61, 0x74, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x05, 0x63, 0x68, 0x61, 0x74, 0x73, 0x2a, 0x50, 0x0a, 0x08,
-	0x43, 0x68, 0x61, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x19, 0x0a, 0x15, 0x43, 0x48, 0x41, 0x54,
-	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45,
-	0x44, 0x10, 0x00, 0x12, 0x14, 0x0a, 0x10, 0x43, 0x48, 0x41, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45,
-	0x5f, 0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x43, 0x48, 0x41,
-	0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x47, 0x52, 0x4f, 0x55, 0x50, 0x10, 0x02, 0x42, 0x28,
-	0x5a, 0x26, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x41, 0x79, 0x6f,
-	0x62, 0x61, 0x6d, 0x69, 0x30, 0x2f, 0x63, 0x6c, 0x69, 0x2d, 0x63, 0x68, 0x61, 0x74, 0x2d, 0x73,
-	0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x72, 0x6f, 0x75, 0x70, 0x50, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x22, 0xdb, 0x03, 0x0a, 0x0f,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x68, 0x61, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x17, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x63, 0x68, 0x61, 0x74, 0x49, 0x64, 0x12, 0x34, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x68, 0x61, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x27,
+	0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a,
+	0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x48, 0x00, 0x52, 0x06, 0x6d, 0x65,
+	0x6d, 0x62, 0x65, 0x72, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x1d, 0x0a, 0x07, 0x70, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x48, 0x02, 0x52, 0x07, 0x70, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x88, 0x01, 0x01, 0x12,
+	0x22, 0x0a, 0x0a, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x48, 0x03, 0x52, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64,
+	0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x0f, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x6f,
+	0x6c, 0x65, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x22, 0x9f, 0x01, 0x0a, 0x06, 0x41, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x12, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e,
+	0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x41,
+	0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x4b, 0x49, 0x43, 0x4b, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a,
+	0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x42, 0x41, 0x4e, 0x10, 0x02, 0x12, 0x11, 0x0a, 0x0d,
+	0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x52, 0x45, 0x4e, 0x41, 0x4d, 0x45, 0x10, 0x03, 0x12,
+	0x19, 0x0a, 0x15, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x52, 0x4f, 0x54, 0x41, 0x54, 0x45,
+	0x5f, 0x50, 0x41, 0x53, 0x53, 0x4b, 0x45, 0x59, 0x10, 0x04, 0x12, 0x19, 0x0a, 0x15, 0x41, 0x43,
+	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x4d, 0x45, 0x53, 0x53,
+	0x41, 0x47, 0x45, 0x10, 0x05, 0x12, 0x13, 0x0a, 0x0f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f,
+	0x53, 0x45, 0x54, 0x5f, 0x52, 0x4f, 0x4c, 0x45, 0x10, 0x06, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x6d,
+	0x65, 0x6d, 0x62, 0x65, 0x72, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x0a,
+	0x0a, 0x08, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x6b, 0x65, 0x79, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x22, 0x51, 0x0a, 0x0a, 0x4d, 0x65, 0x6d,
+	0x62, 0x65, 0x72, 0x52, 0x6f, 0x6c, 0x65, 0x12, 0x1e, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x55, 0x73, 0x65,
+	0x72, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x23, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x47, 0x72, 0x6f,
+	0x75, 0x70, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x22, 0x41, 0x0a, 0x0b,
+	0x43, 0x68, 0x61, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x22, 0x0a, 0x04, 0x63,
+	0x68, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x63, 0x68, 0x61, 0x74,
+	0x2e, 0x43, 0x68, 0x61, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x63, 0x68, 0x61, 0x74, 0x22,
+	0xcd, 0x02, 0x0a, 0x0c, 0x43, 0x68, 0x61, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x29, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x52, 0x08, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x07, 0x6d,
+	0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x63,
+	0x68, 0x61, 0x74, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72,
+	0x73, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x22, 0x0a, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x63, 0x68, 0x61,
+	0x74, 0x2e, 0x43, 0x68, 0x61, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x33, 0x0a, 0x0c, 0x72, 0x65, 0x61,
+	0x64, 0x5f, 0x6d, 0x61, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x52, 0x65, 0x61, 0x64, 0x4d, 0x61, 0x72, 0x6b, 0x65,
+	0x72, 0x52, 0x0b, 0x72, 0x65, 0x61, 0x64, 0x4d, 0x61, 0x72, 0x6b, 0x65, 0x72, 0x73, 0x12, 0x26,
+	0x0a, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e,
+	0x63, 0x68, 0x61, 0x74, 0x2e, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x52, 0x6f, 0x6c, 0x65, 0x52,
+	0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x22,
+	0x39, 0x0a, 0x0d, 0x43, 0x68, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x28, 0x0a, 0x05, 0x63, 0x68, 0x61, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x63, 0x68, 0x61, 0x74, 0x2e, 0x43, 0x68, 0x61, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x52, 0x05, 0x63, 0x68, 0x61, 0x74, 0x73, 0x2a, 0x50, 0x0a, 0x08, 0x43, 0x68,
+	0x61, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x19, 0x0a, 0x15, 0x43, 0x48, 0x41, 0x54, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x14, 0x0a, 0x10, 0x43, 0x48, 0x41, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44,
+	0x49, 0x52, 0x45, 0x43, 0x54, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x43, 0x48, 0x41, 0x54, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x47, 0x52, 0x4f, 0x55, 0x50, 0x10, 0x02, 0x2a, 0x6a, 0x0a, 0x09,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x6f, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x16, 0x47, 0x52, 0x4f,
+	0x55, 0x50, 0x5f, 0x52, 0x4f, 0x4c, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46,
+	0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x47, 0x52, 0x4f, 0x55, 0x50, 0x5f, 0x52,
+	0x4f, 0x4c, 0x45, 0x5f, 0x4d, 0x45, 0x4d, 0x42, 0x45, 0x52, 0x10, 0x01, 0x12, 0x14, 0x0a, 0x10,
+	0x47, 0x52, 0x4f, 0x55, 0x50, 0x5f, 0x52, 0x4f, 0x4c, 0x45, 0x5f, 0x41, 0x44, 0x4d, 0x49, 0x4e,
+	0x10, 0x02, 0x12, 0x14, 0x0a, 0x10, 0x47, 0x52, 0x4f, 0x55, 0x50, 0x5f, 0x52, 0x4f, 0x4c, 0x45,
+	0x5f, 0x4f, 0x57, 0x4e, 0x45, 0x52, 0x10, 0x03, 0x42, 0x28, 0x5a, 0x26, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x41, 0x79, 0x6f, 0x62, 0x61, 0x6d, 0x69, 0x30, 0x2f,
+	0x63, 0x6c, 0x69, 0x2d, 0x63, 0x68, 0x61, 0x74, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -663,40 +994,52 @@ func file_chat_message_proto_rawDescGZIP() []byte {
 	return file_chat_message_proto_rawDescData
 }
 
-var file_chat_message_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_chat_message_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_chat_message_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_chat_message_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_chat_message_proto_goTypes = []interface{}{
 	(ChatType)(0),                   // 0: chat.ChatType
-	(DirectChatAction_Action)(0),    // 1: chat.DirectChatAction.Action
-	(*JoinDirectChatRequest)(nil),   // 2: chat.JoinDirectChatRequest
-	(*JoinDirectChatResponse)(nil),  // 3: chat.JoinDirectChatResponse
-	(*JoinDirectChatResponses)(nil), // 4: chat.JoinDirectChatResponses
-	(*DirectChatAction)(nil),        // 5: chat.DirectChatAction
-	(*GroupChatRequest)(nil),        // 6: chat.GroupChatRequest
-	(*ChatRequest)(nil),             // 7: chat.ChatRequest
-	(*ChatResponse)(nil),            // 8: chat.ChatResponse
-	(*ChatsResponse)(nil),           // 9: chat.ChatsResponse
-	(*User)(nil),                    // 10: chat.User
-	(*timestamppb.Timestamp)(nil),   // 11: google.protobuf.Timestamp
-	(*Message)(nil),                 // 12: chat.Message
+	(GroupRole)(0),                  // 1: chat.GroupRole
+	(DirectChatAction_Action)(0),    // 2: chat.DirectChatAction.Action
+	(GroupChatAction_Action)(0),     // 3: chat.GroupChatAction.Action
+	(*JoinDirectChatRequest)(nil),   // 4: chat.JoinDirectChatRequest
+	(*JoinDirectChatResponse)(nil),  // 5: chat.JoinDirectChatResponse
+	(*JoinDirectChatResponses)(nil), // 6: chat.JoinDirectChatResponses
+	(*DirectChatAction)(nil),        // 7: chat.DirectChatAction
+	(*GroupChatRequest)(nil),        // 8: chat.GroupChatRequest
+	(*GroupChatAction)(nil),         // 9: chat.GroupChatAction
+	(*MemberRole)(nil),              // 10: chat.MemberRole
+	(*ChatRequest)(nil),             // 11: chat.ChatRequest
+	(*ChatResponse)(nil),            // 12: chat.ChatResponse
+	(*ChatsResponse)(nil),           // 13: chat.ChatsResponse
+	(*User)(nil),                    // 14: chat.User
+	(*timestamppb.Timestamp)(nil),   // 15: google.protobuf.Timestamp
+	(*Message)(nil),                 // 16: chat.Message
+	(*ReadMarker)(nil),              // 17: chat.ReadMarker
 }
 var file_chat_message_proto_depIdxs = []int32{
-	10, // 0: chat.JoinDirectChatRequest.receiver:type_name -> chat.User
-	11, // 1: chat.JoinDirectChatRequest.sent_at:type_name -> google.protobuf.Timestamp
-	10, // 2: chat.JoinDirectChatResponse.sender:type_name -> chat.User
-	3,  // 3: chat.JoinDirectChatResponses.requests:type_name -> chat.JoinDirectChatResponse
-	1,  // 4: chat.DirectChatAction.action:type_name -> chat.DirectChatAction.Action
-	0,  // 5: chat.ChatRequest.chat:type_name -> chat.ChatType
-	12, // 6: chat.ChatResponse.messages:type_name -> chat.Message
-	10, // 7: chat.ChatResponse.members:type_name -> chat.User
-	11, // 8: chat.ChatResponse.created_at:type_name -> google.protobuf.Timestamp
-	0,  // 9: chat.ChatResponse.type:type_name -> chat.ChatType
-	8,  // 10: chat.ChatsResponse.chats:type_name -> chat.ChatResponse
-	11, // [11:11] is the sub-list for method output_type
-	11, // [11:11] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	14, // 0: chat.JoinDirectChatRequest.receiver:type_name -> chat.User
+	15, // 1: chat.JoinDirectChatRequest.sent_at:type_name -> google.protobuf.Timestamp
+	14, // 2: chat.JoinDirectChatResponse.sender:type_name -> chat.User
+	5,  // 3: chat.JoinDirectChatResponses.requests:type_name -> chat.JoinDirectChatResponse
+	2,  // 4: chat.DirectChatAction.action:type_name -> chat.DirectChatAction.Action
+	3,  // 5: chat.GroupChatAction.action:type_name -> chat.GroupChatAction.Action
+	14, // 6: chat.GroupChatAction.member:type_name -> chat.User
+	1,  // 7: chat.GroupChatAction.role:type_name -> chat.GroupRole
+	14, // 8: chat.MemberRole.user:type_name -> chat.User
+	1,  // 9: chat.MemberRole.role:type_name -> chat.GroupRole
+	0,  // 10: chat.ChatRequest.chat:type_name -> chat.ChatType
+	16, // 11: chat.ChatResponse.messages:type_name -> chat.Message
+	14, // 12: chat.ChatResponse.members:type_name -> chat.User
+	15, // 13: chat.ChatResponse.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 14: chat.ChatResponse.type:type_name -> chat.ChatType
+	17, // 15: chat.ChatResponse.read_markers:type_name -> chat.ReadMarker
+	10, // 16: chat.ChatResponse.roles:type_name -> chat.MemberRole
+	12, // 17: chat.ChatsResponse.chats:type_name -> chat.ChatResponse
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_chat_message_proto_init() }
@@ -768,7 +1111,7 @@ func file_chat_message_proto_init() {
 			}
 		}
 		file_chat_message_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ChatRequest); i {
+			switch v := v.(*GroupChatAction); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -780,7 +1123,7 @@ func file_chat_message_proto_init() {
 			}
 		}
 		file_chat_message_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ChatResponse); i {
+			switch v := v.(*MemberRole); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -792,6 +1135,30 @@ func file_chat_message_proto_init() {
 			}
 		}
 		file_chat_message_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChatRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chat_message_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChatResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_chat_message_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ChatsResponse); i {
 			case 0:
 				return &v.state
@@ -804,14 +1171,15 @@ func file_chat_message_proto_init() {
 			}
 		}
 	}
-	file_chat_message_proto_msgTypes[6].OneofWrappers = []interface{}{}
+	file_chat_message_proto_msgTypes[5].OneofWrappers = []interface{}{}
+	file_chat_message_proto_msgTypes[8].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_chat_message_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   8,
+			NumEnums:      4,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
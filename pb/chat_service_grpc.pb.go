@@ -32,6 +32,11 @@ type ChatServiceClient interface {
 	GetChats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ChatsResponse, error)
 	CreateGroupChat(ctx context.Context, in *GroupChatRequest, opts ...grpc.CallOption) (*ChatResponse, error)
 	DirectChatRequestAction(ctx context.Context, in *DirectChatAction, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GroupChatAdminAction(ctx context.Context, in *GroupChatAction, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	StartUpload(ctx context.Context, in *UploadRequest, opts ...grpc.CallOption) (*UploadStatus, error)
+	UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (ChatService_UploadAttachmentClient, error)
+	DownloadAttachment(ctx context.Context, in *DownloadRequest, opts ...grpc.CallOption) (ChatService_DownloadAttachmentClient, error)
+	PresenceStream(ctx context.Context, opts ...grpc.CallOption) (ChatService_PresenceStreamClient, error)
 }
 
 type chatServiceClient struct {
@@ -145,6 +150,121 @@ func (c *chatServiceClient) DirectChatRequestAction(ctx context.Context, in *Dir
 	return out, nil
 }
 
+func (c *chatServiceClient) GroupChatAdminAction(ctx context.Context, in *GroupChatAction, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/GroupChatAdminAction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) StartUpload(ctx context.Context, in *UploadRequest, opts ...grpc.CallOption) (*UploadStatus, error) {
+	out := new(UploadStatus)
+	err := c.cc.Invoke(ctx, "/chat.ChatService/StartUpload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chatServiceClient) UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (ChatService_UploadAttachmentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ChatService_ServiceDesc.Streams[1], "/chat.ChatService/UploadAttachment", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatServiceUploadAttachmentClient{stream}
+	return x, nil
+}
+
+type ChatService_UploadAttachmentClient interface {
+	Send(*AttachmentChunk) error
+	CloseAndRecv() (*Attachment, error)
+	grpc.ClientStream
+}
+
+type chatServiceUploadAttachmentClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatServiceUploadAttachmentClient) Send(m *AttachmentChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *chatServiceUploadAttachmentClient) CloseAndRecv() (*Attachment, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Attachment)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatServiceClient) DownloadAttachment(ctx context.Context, in *DownloadRequest, opts ...grpc.CallOption) (ChatService_DownloadAttachmentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ChatService_ServiceDesc.Streams[2], "/chat.ChatService/DownloadAttachment", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatServiceDownloadAttachmentClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ChatService_DownloadAttachmentClient interface {
+	Recv() (*AttachmentChunk, error)
+	grpc.ClientStream
+}
+
+type chatServiceDownloadAttachmentClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatServiceDownloadAttachmentClient) Recv() (*AttachmentChunk, error) {
+	m := new(AttachmentChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *chatServiceClient) PresenceStream(ctx context.Context, opts ...grpc.CallOption) (ChatService_PresenceStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ChatService_ServiceDesc.Streams[3], "/chat.ChatService/PresenceStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &chatServicePresenceStreamClient{stream}
+	return x, nil
+}
+
+type ChatService_PresenceStreamClient interface {
+	Send(*PresenceUpdate) error
+	Recv() (*Presence, error)
+	grpc.ClientStream
+}
+
+type chatServicePresenceStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *chatServicePresenceStreamClient) Send(m *PresenceUpdate) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *chatServicePresenceStreamClient) Recv() (*Presence, error) {
+	m := new(Presence)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ChatServiceServer is the server API for ChatService service.
 // All implementations must embed UnimplementedChatServiceServer
 // for forward compatibility
@@ -158,6 +278,11 @@ type ChatServiceServer interface {
 	GetChats(context.Context, *emptypb.Empty) (*ChatsResponse, error)
 	CreateGroupChat(context.Context, *GroupChatRequest) (*ChatResponse, error)
 	DirectChatRequestAction(context.Context, *DirectChatAction) (*emptypb.Empty, error)
+	GroupChatAdminAction(context.Context, *GroupChatAction) (*emptypb.Empty, error)
+	StartUpload(context.Context, *UploadRequest) (*UploadStatus, error)
+	UploadAttachment(ChatService_UploadAttachmentServer) error
+	DownloadAttachment(*DownloadRequest, ChatService_DownloadAttachmentServer) error
+	PresenceStream(ChatService_PresenceStreamServer) error
 	mustEmbedUnimplementedChatServiceServer()
 }
 
@@ -192,6 +317,21 @@ func (UnimplementedChatServiceServer) CreateGroupChat(context.Context, *GroupCha
 func (UnimplementedChatServiceServer) DirectChatRequestAction(context.Context, *DirectChatAction) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DirectChatRequestAction not implemented")
 }
+func (UnimplementedChatServiceServer) GroupChatAdminAction(context.Context, *GroupChatAction) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GroupChatAdminAction not implemented")
+}
+func (UnimplementedChatServiceServer) StartUpload(context.Context, *UploadRequest) (*UploadStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartUpload not implemented")
+}
+func (UnimplementedChatServiceServer) UploadAttachment(ChatService_UploadAttachmentServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadAttachment not implemented")
+}
+func (UnimplementedChatServiceServer) DownloadAttachment(*DownloadRequest, ChatService_DownloadAttachmentServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadAttachment not implemented")
+}
+func (UnimplementedChatServiceServer) PresenceStream(ChatService_PresenceStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method PresenceStream not implemented")
+}
 func (UnimplementedChatServiceServer) mustEmbedUnimplementedChatServiceServer() {}
 
 // UnsafeChatServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -375,6 +515,115 @@ func _ChatService_DirectChatRequestAction_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ChatService_GroupChatAdminAction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupChatAction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).GroupChatAdminAction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/GroupChatAdminAction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).GroupChatAdminAction(ctx, req.(*GroupChatAction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_StartUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChatServiceServer).StartUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/chat.ChatService/StartUpload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChatServiceServer).StartUpload(ctx, req.(*UploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChatService_UploadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChatServiceServer).UploadAttachment(&chatServiceUploadAttachmentServer{stream})
+}
+
+type ChatService_UploadAttachmentServer interface {
+	SendAndClose(*Attachment) error
+	Recv() (*AttachmentChunk, error)
+	grpc.ServerStream
+}
+
+type chatServiceUploadAttachmentServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatServiceUploadAttachmentServer) SendAndClose(m *Attachment) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *chatServiceUploadAttachmentServer) Recv() (*AttachmentChunk, error) {
+	m := new(AttachmentChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ChatService_DownloadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChatServiceServer).DownloadAttachment(m, &chatServiceDownloadAttachmentServer{stream})
+}
+
+type ChatService_DownloadAttachmentServer interface {
+	Send(*AttachmentChunk) error
+	grpc.ServerStream
+}
+
+type chatServiceDownloadAttachmentServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatServiceDownloadAttachmentServer) Send(m *AttachmentChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ChatService_PresenceStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ChatServiceServer).PresenceStream(&chatServicePresenceStreamServer{stream})
+}
+
+type ChatService_PresenceStreamServer interface {
+	Send(*Presence) error
+	Recv() (*PresenceUpdate, error)
+	grpc.ServerStream
+}
+
+type chatServicePresenceStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *chatServicePresenceStreamServer) Send(m *Presence) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *chatServicePresenceStreamServer) Recv() (*PresenceUpdate, error) {
+	m := new(PresenceUpdate)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ChatService_ServiceDesc is the grpc.ServiceDesc for ChatService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -414,6 +663,14 @@ var ChatService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DirectChatRequestAction",
 			Handler:    _ChatService_DirectChatRequestAction_Handler,
 		},
+		{
+			MethodName: "GroupChatAdminAction",
+			Handler:    _ChatService_GroupChatAdminAction_Handler,
+		},
+		{
+			MethodName: "StartUpload",
+			Handler:    _ChatService_StartUpload_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -422,6 +679,22 @@ var ChatService_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "UploadAttachment",
+			Handler:       _ChatService_UploadAttachment_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadAttachment",
+			Handler:       _ChatService_DownloadAttachment_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "PresenceStream",
+			Handler:       _ChatService_PresenceStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "chat_service.proto",
 }
@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+	"google.golang.org/grpc/metadata"
+)
+
+// Returns the role of username in a group
+func (c chatItem) role(username string) pb.GroupRole {
+	if role, ok := c.roles[username]; ok {
+		return role
+	}
+	return pb.GroupRole_GROUP_ROLE_MEMBER
+}
+
+// Returns the name a role is shown with, or nothing for members
+func roleName(role pb.GroupRole) string {
+	switch role {
+	case pb.GroupRole_GROUP_ROLE_OWNER:
+		return "owner"
+	case pb.GroupRole_GROUP_ROLE_ADMIN:
+		return "admin"
+	}
+	return ""
+}
+
+// Returns the active chat if it is a group the user moderates
+func (m chatModel) moderatedChat() (chatItem, error) {
+	_, chat, ok := m.activeChatItem()
+	if !ok || chat.chatType != pb.ChatType_CHAT_TYPE_GROUP {
+		return chat, errors.New("Open a group chat first")
+	}
+	if chat.role(m.user.Username) < pb.GroupRole_GROUP_ROLE_ADMIN {
+		return chat, errors.New("Only owners and admins can do that")
+	}
+	return chat, nil
+}
+
+// Runs a group administration command on the active chat. Only what the
+// server would refuse anyway is checked.
+func (m chatModel) groupCommand(name string, args []string) (tea.Cmd, error) {
+	chat, err := m.moderatedChat()
+	if err != nil {
+		return nil, err
+	}
+	action := &pb.GroupChatAction{ChatId: chat.id}
+
+	switch name {
+	case "/rename":
+		action.Action = pb.GroupChatAction_ACTION_RENAME
+		action.Name = &args[0]
+		return m.sendGroupAction(action, "Renamed the group to "+args[0]), nil
+	case "/passkey":
+		action.Action = pb.GroupChatAction_ACTION_ROTATE_PASSKEY
+		action.Passkey = &args[0]
+		return m.sendGroupAction(action, "Changed the passkey"), nil
+	}
+
+	username := args[0]
+	var member *pb.User
+	for _, u := range chat.members {
+		if u.Username == username {
+			member = u
+		}
+	}
+	switch {
+	case member == nil:
+		return nil, fmt.Errorf("%s is not in this group", username)
+	case username == m.user.Username:
+		return nil, fmt.Errorf("You cannot %s yourself", name[1:])
+	case chat.role(username) >= chat.role(m.user.Username):
+		return nil, fmt.Errorf("You cannot %s %s", name[1:], username)
+	}
+	action.Member = member
+
+	var done string
+	switch name {
+	case "/kick":
+		action.Action = pb.GroupChatAction_ACTION_KICK
+		done = "Kicked " + username
+	case "/ban":
+		action.Action = pb.GroupChatAction_ACTION_BAN
+		done = "Banned " + username
+	case "/promote", "/demote":
+		if chat.role(m.user.Username) != pb.GroupRole_GROUP_ROLE_OWNER {
+			return nil, errors.New("Only the owner can change who is an admin")
+		}
+		action.Action = pb.GroupChatAction_ACTION_SET_ROLE
+		action.Role = pb.GroupRole_GROUP_ROLE_ADMIN
+		done = "Made " + username + " an admin"
+		if name == "/demote" {
+			if chat.role(username) != pb.GroupRole_GROUP_ROLE_ADMIN {
+				return nil, fmt.Errorf("%s is not an admin", username)
+			}
+			action.Role = pb.GroupRole_GROUP_ROLE_MEMBER
+			done = username + " is no longer an admin"
+		} else if chat.role(username) == pb.GroupRole_GROUP_ROLE_ADMIN {
+			return nil, fmt.Errorf("%s is already an admin", username)
+		}
+	}
+	return m.sendGroupAction(action, done), nil
+}
+
+// Deletes the selected message from the active group. The first press only
+// asks for the deletion to be confirmed by pressing again.
+func (m *chatModel) deleteSelectedMessage() tea.Cmd {
+	chat, err := m.moderatedChat()
+	if err != nil {
+		m.msg = err.Error()
+		return nil
+	}
+	if m.selectedMsg >= len(chat.messages) {
+		return nil
+	}
+	msg := chat.messages[m.selectedMsg]
+	if msg.Id == "" || msg.Type == pb.Message_MESSAGE_TYPE_NOTIFICATION {
+		m.msg = "Cannot delete this message"
+		return nil
+	}
+	if sender := msg.Sender.GetUsername(); sender != m.user.Username && chat.role(sender) >= chat.role(m.user.Username) {
+		m.msg = "You cannot delete messages from " + sender
+		return nil
+	}
+	if m.confirmDelete != msg.Id {
+		m.confirmDelete = msg.Id
+		m.msg = notificationTextStyle.Render("Press " + m.keys.Delete.Help().Key + " again to delete the message")
+		return nil
+	}
+	m.confirmDelete = ""
+	return m.sendGroupAction(&pb.GroupChatAction{
+		ChatId:    chat.id,
+		Action:    pb.GroupChatAction_ACTION_DELETE_MESSAGE,
+		MessageId: &msg.Id,
+	}, "Deleted the message")
+}
+
+// Removes a deleted message from the active chat
+func (m *chatModel) receiveDeletion(d *pb.Deletion) {
+	i, chat, ok := m.activeChatItem()
+	if !ok {
+		return
+	}
+	msgs := make([]*pb.Message, 0, len(chat.messages))
+	for _, msg := range chat.messages {
+		if msg.Id != d.MessageId {
+			msgs = append(msgs, msg)
+		}
+	}
+	chat.messages = msgs
+	m.chatList.SetItem(i, chat)
+	m.renderMessages()
+}
+
+// Sends an action to the server, which reports it to the group. done is
+// shown once it is carried out.
+func (c chatModel) sendGroupAction(action *pb.GroupChatAction, done string) tea.Cmd {
+	return func() tea.Msg {
+		meta := metadata.Pairs("authorization", fmt.Sprintf("Bearer %s", c.sessionToken))
+
+		ctx := metadata.NewOutgoingContext(c.session, meta)
+
+		_, err := c.client.GroupChatAdminAction(ctx, action)
+		if err != nil {
+			return errMsg{err}
+		}
+
+		return statusMsg{sType: STATUS_GROUP_ACTION_SEND, sRes: done}
+	}
+}
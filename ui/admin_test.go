@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+)
+
+func TestDeleteSelectedMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   pb.GroupRole
+		sender string
+		want   bool
+	}{
+		{"member", pb.GroupRole_GROUP_ROLE_MEMBER, "alice", false},
+		{"admin, member's message", pb.GroupRole_GROUP_ROLE_ADMIN, "alice", true},
+		{"admin, own message", pb.GroupRole_GROUP_ROLE_ADMIN, "me", true},
+		{"admin, admin's message", pb.GroupRole_GROUP_ROLE_ADMIN, "bob", false},
+		{"admin, owner's message", pb.GroupRole_GROUP_ROLE_ADMIN, "carol", false},
+		{"owner, admin's message", pb.GroupRole_GROUP_ROLE_OWNER, "bob", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles := map[string]pb.GroupRole{
+				"bob":   pb.GroupRole_GROUP_ROLE_ADMIN,
+				"carol": pb.GroupRole_GROUP_ROLE_OWNER,
+			}
+			if tt.role != pb.GroupRole_GROUP_ROLE_OWNER {
+				roles["me"] = tt.role
+			} else {
+				roles["carol"], roles["me"] = pb.GroupRole_GROUP_ROLE_ADMIN, tt.role
+			}
+			m := newTestChatModel(chatItem{
+				id:       "a",
+				name:     "a",
+				chatType: pb.ChatType_CHAT_TYPE_GROUP,
+				roles:    roles,
+				messages: []*pb.Message{testMessage("1", tt.sender, time.Now())},
+			})
+			m.activeChat = "a"
+			m.selectedMsg = 0
+
+			// Confirmed by pressing twice
+			m.deleteSelectedMessage()
+			got := m.deleteSelectedMessage() != nil
+			if got != tt.want {
+				t.Errorf("deleted = %v, want %v, msg %q", got, tt.want, m.msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package ui
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	attachmentChunkSize = 64 * 1024
+	// Number of times a failed transfer is resumed before giving up
+	maxTransferAttempts = 3
+)
+
+type uploadResult struct {
+	chatID     string
+	attachment *pb.Attachment
+}
+
+// Parses a path typed or dropped into the message input. Terminals paste
+// dropped files as quoted or escaped paths, or as file:// urls.
+func parseAttachmentPath(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	}
+	if strings.HasPrefix(s, "file://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return "", false
+		}
+		s = u.Path
+	}
+	s = strings.ReplaceAll(s, `\ `, " ")
+	if strings.HasPrefix(s, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		s = filepath.Join(home, s[2:])
+	}
+
+	info, err := os.Stat(s)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return s, true
+}
+
+// Formats a size in bytes i.e. 12.3 KiB
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Returns the last element of a file name given by another user, so saving
+// the file cannot reach outside the directory it is saved to
+func cleanFileName(name string) (string, error) {
+	base := filepath.Base(name)
+	if base == "." || base == ".." || base == string(filepath.Separator) {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	return base, nil
+}
+
+// Joins dir and a file name given by another user, checking the path is
+// still inside dir
+func pathInDir(dir, name string) (string, error) {
+	clean, err := cleanFileName(name)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, clean)
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel != clean {
+		return "", fmt.Errorf("invalid file name %q", name)
+	}
+	return path, nil
+}
+
+// Returns a path in dir for name that does not overwrite an existing file
+// i.e. report (1).log
+func availablePath(dir, name string) (string, error) {
+	path, err := pathInDir(dir, name)
+	if err != nil {
+		return "", err
+	}
+	name = filepath.Base(path)
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path, nil
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+	}
+}
+
+func (m chatModel) formatAttachment(att *pb.Attachment) string {
+	return attachmentTextStyle.Render(fmt.Sprintf("%c %s (%s)", ICON_ATTACHMENT, att.Name, humanSize(att.Size)))
+}
+
+// Uploads the file at path to the chat. The server reports how much of a file
+// it already has, so an interrupted upload continues where it stopped.
+func (c chatModel) uploadAttachment(chatID, path string) tea.Cmd {
+	return func() tea.Msg {
+		meta := metadata.Pairs("authorization", fmt.Sprintf("Bearer %s", c.sessionToken))
+
+		ctx := metadata.NewOutgoingContext(c.session, meta)
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return errMsg{err}
+		}
+		checksum, err := fileChecksum(path)
+		if err != nil {
+			return errMsg{err}
+		}
+
+		req := &pb.UploadRequest{
+			ChatId:   chatID,
+			Name:     filepath.Base(path),
+			Size:     info.Size(),
+			Sha256:   checksum,
+			MimeType: mime.TypeByExtension(filepath.Ext(path)),
+		}
+
+		var att *pb.Attachment
+		for attempt := 1; ; attempt++ {
+			att, err = c.sendAttachmentChunks(ctx, path, req)
+			if err == nil || attempt == maxTransferAttempts {
+				break
+			}
+		}
+		if err != nil {
+			return errMsg{err}
+		}
+
+		return statusMsg{sType: STATUS_ATTACHMENT_UPLOAD, sRes: uploadResult{chatID: chatID, attachment: att}}
+	}
+}
+
+func (c chatModel) sendAttachmentChunks(ctx context.Context, path string, req *pb.UploadRequest) (*pb.Attachment, error) {
+	status, err := c.client.StartUpload(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(status.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	stream, err := c.client.UploadAttachment(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, attachmentChunkSize)
+	offset := status.Offset
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			err := stream.Send(&pb.AttachmentChunk{
+				AttachmentId: status.AttachmentId,
+				Offset:       offset,
+				Data:         buf[:n],
+				Crc32:        crc32.ChecksumIEEE(buf[:n]),
+			})
+			if err != nil {
+				return nil, err
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
+
+// Downloads an attachment into dir. Data is written to a .part file which is
+// resumed from if a previous download was interrupted.
+func (c chatModel) downloadAttachment(att *pb.Attachment, dir string) tea.Cmd {
+	return func() tea.Msg {
+		meta := metadata.Pairs("authorization", fmt.Sprintf("Bearer %s", c.sessionToken))
+
+		ctx := metadata.NewOutgoingContext(c.session, meta)
+
+		name, err := cleanFileName(att.Name)
+		if err != nil {
+			return errMsg{err}
+		}
+		// Named after the attachment so a download only resumes its own
+		// bytes, whatever other attachments of the same name left behind
+		id, err := cleanFileName(att.Id)
+		if err != nil || id != att.Id {
+			return errMsg{fmt.Errorf("invalid attachment id %q", att.Id)}
+		}
+		part, err := pathInDir(dir, "."+id+".part")
+		if err != nil {
+			return errMsg{err}
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return errMsg{err}
+		}
+
+		if err := c.fetchAttachment(ctx, att, part); err != nil {
+			return errMsg{err}
+		}
+
+		path, err := availablePath(dir, name)
+		if err != nil {
+			return errMsg{err}
+		}
+		if err := os.Rename(part, path); err != nil {
+			return errMsg{err}
+		}
+
+		return statusMsg{sType: STATUS_ATTACHMENT_DOWNLOAD, sRes: path}
+	}
+}
+
+// Downloads an attachment to part, resuming interrupted transfers, and checks
+// it was received intact
+func (c chatModel) fetchAttachment(ctx context.Context, att *pb.Attachment, part string) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = c.receiveAttachmentChunks(ctx, att, part)
+		if err == nil || attempt == maxTransferAttempts {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	checksum, err := fileChecksum(part)
+	if err != nil {
+		return err
+	}
+	if att.Sha256 != "" && checksum != att.Sha256 {
+		os.Remove(part)
+		return fmt.Errorf("download of %s failed: checksum mismatch", att.Name)
+	}
+	return nil
+}
+
+func (c chatModel) receiveAttachmentChunks(ctx context.Context, att *pb.Attachment, part string) error {
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	offset := info.Size()
+	if offset >= att.Size {
+		return nil
+	}
+
+	stream, err := c.client.DownloadAttachment(ctx, &pb.DownloadRequest{AttachmentId: att.Id, Offset: offset})
+	if err != nil {
+		return err
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if chunk.Offset != offset {
+			return fmt.Errorf("download of %s failed: expected chunk at %d, got %d", att.Name, offset, chunk.Offset)
+		}
+		if crc32.ChecksumIEEE(chunk.Data) != chunk.Crc32 {
+			return fmt.Errorf("download of %s failed: corrupted chunk at %d", att.Name, offset)
+		}
+		if _, err := f.Write(chunk.Data); err != nil {
+			return err
+		}
+		offset += int64(len(chunk.Data))
+	}
+}
@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	"google.golang.org/grpc"
+)
+
+func TestParseAttachmentPath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "my file.txt")
+	if err := os.WriteFile(file, []byte("hi"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{file, file, true},
+		{"  " + file + "\n", file, true},
+		{"'" + file + "'", file, true},
+		{`"` + file + `"`, file, true},
+		{strings.ReplaceAll(file, " ", `\ `), file, true},
+		{"file://" + strings.ReplaceAll(file, " ", "%20"), file, true},
+		{dir, "", false},
+		{filepath.Join(dir, "missing.txt"), "", false},
+		{"hello there", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseAttachmentPath(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseAttachmentPath(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{12595, "12.3 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GiB"},
+	}
+	for _, tt := range tests {
+		if got := humanSize(tt.n); got != tt.want {
+			t.Errorf("humanSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestPathInDirStaysInDir(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"report.pdf", "report.pdf", false},
+		{"../../.bashrc", ".bashrc", false},
+		{"/etc/passwd", "passwd", false},
+		{"a/b/c.txt", "c.txt", false},
+		{"../../.bashrc.part", ".bashrc.part", false},
+		{"", "", true},
+		{".", "", true},
+		{"..", "", true},
+		{"/", "", true},
+		{"a/..", "", true},
+	}
+	for _, tt := range tests {
+		got, err := pathInDir(dir, tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("pathInDir(%q) = %q, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil || got != filepath.Join(dir, tt.want) {
+			t.Errorf("pathInDir(%q) = %q, %v, want %q", tt.name, got, err, filepath.Join(dir, tt.want))
+		}
+	}
+}
+
+func TestAvailablePath(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"report.log", "report (1).log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"new.log", "new.log", false},
+		{"report.log", "report (2).log", false},
+		{"../report.log", "report (2).log", false},
+		{"..", "", true},
+	}
+	for _, tt := range tests {
+		got, err := availablePath(dir, tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("availablePath(%q) = %q, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil || got != filepath.Join(dir, tt.want) {
+			t.Errorf("availablePath(%q) = %q, %v, want %q", tt.name, got, err, filepath.Join(dir, tt.want))
+		}
+	}
+}
+
+// A client serving attachments in chunks of 4 bytes
+type fakeAttachmentClient struct {
+	pb.ChatServiceClient
+	data      map[string][]byte // by attachment id
+	interrupt map[string]bool   // downloads of the attachment end after a chunk
+}
+
+func (c *fakeAttachmentClient) DownloadAttachment(ctx context.Context, in *pb.DownloadRequest, opts ...grpc.CallOption) (pb.ChatService_DownloadAttachmentClient, error) {
+	return &fakeDownloadStream{data: c.data[in.AttachmentId], offset: in.Offset, interrupt: c.interrupt[in.AttachmentId]}, nil
+}
+
+type fakeDownloadStream struct {
+	grpc.ClientStream
+	data      []byte
+	offset    int64
+	interrupt bool
+	sent      int
+}
+
+func (s *fakeDownloadStream) Recv() (*pb.AttachmentChunk, error) {
+	if s.interrupt && s.sent == 1 {
+		return nil, errors.New("connection lost")
+	}
+	if s.offset >= int64(len(s.data)) {
+		return nil, io.EOF
+	}
+	data := s.data[s.offset:min(s.offset+4, int64(len(s.data)))]
+	chunk := &pb.AttachmentChunk{Offset: s.offset, Data: data, Crc32: crc32.ChecksumIEEE(data)}
+	s.offset += int64(len(data))
+	s.sent++
+	return chunk, nil
+}
+
+func TestDownloadsOfTheSameNameDoNotMix(t *testing.T) {
+	client := &fakeAttachmentClient{
+		data: map[string][]byte{
+			"a": []byte("first report, long enough to be left unfinished"),
+			"b": []byte("SECOND REPORT"),
+		},
+		interrupt: map[string]bool{},
+	}
+	m := newTestChatModel()
+	m.client = client
+	dir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		id        string
+		interrupt bool
+		want      string // saved content, empty if the download fails
+	}{
+		{"interrupted", "a", true, ""},
+		{"other attachment of the same name", "b", false, "SECOND REPORT"},
+		{"resumed", "a", false, "first report, long enough to be left unfinished"},
+	}
+	for _, tt := range tests {
+		client.interrupt[tt.id] = tt.interrupt
+		att := &pb.Attachment{Id: tt.id, Name: "report.pdf", Size: int64(len(client.data[tt.id]))}
+
+		res, ok := m.downloadAttachment(att, dir)().(statusMsg)
+		if ok != (tt.want != "") {
+			t.Fatalf("%s: download saved = %v, want %v", tt.name, ok, tt.want != "")
+		}
+		if !ok {
+			continue
+		}
+		if got, err := os.ReadFile(res.sRes.(string)); err != nil || string(got) != tt.want {
+			t.Errorf("%s: saved %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
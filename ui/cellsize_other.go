@@ -0,0 +1,8 @@
+//go:build !unix
+
+package ui
+
+// Returns the size of a terminal cell in pixels
+func cellSize() (int, int) {
+	return defaultCellWidth, defaultCellHeight
+}
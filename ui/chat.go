@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/Ayobami0/cli-chat/pb"
 	"github.com/charmbracelet/bubbles/help"
@@ -28,37 +30,13 @@ const (
 	JOIN_ROOM_PANEL
 	MESSAGE_PANEL
 	MESSSAGE_VIEW_PANEL
+	DETAILS_PANEL
 	MAX_PANEL_NO
 
 	CREATE_GROUP_BTN = 0
 	JOIN_GROUP_BTN   = 1
 )
 
-type keyMap struct {
-	Up          key.Binding
-	Down        key.Binding
-	Help        key.Binding
-	Quit        key.Binding
-	Enter       key.Binding
-	Accept      key.Binding
-	Reject      key.Binding
-	SwitchPanel key.Binding
-	Tab         key.Binding
-}
-
-func (k keyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Help, k.Quit, k.SwitchPanel, k.Tab}
-}
-
-func (k keyMap) FullHelp() [][]key.Binding {
-	return [][]key.Binding{
-		{k.Up, k.Down},
-		{k.Reject, k.Accept},
-		{k.Help, k.Quit},
-		{k.Enter, k.SwitchPanel},
-	}
-}
-
 type chatModel struct {
 	msg                string
 	chatsLoading       bool
@@ -70,7 +48,6 @@ type chatModel struct {
 	help               help.Model
 	chatList           list.Model
 	requestsList       list.Model
-	messages           []string
 	width              int
 	height             int
 	chats              []string
@@ -78,9 +55,6 @@ type chatModel struct {
 	groupFocusedBtn    int
 	groupInputDone     bool // Checks if the passkey and name of a group are entered
 	keys               keyMap
-	helpHeight         int
-	sndRequestHeight   int
-	joinRoomHeight     int
 	loadingMsg         bool
 	addUserInput       textinput.Model
 	nameChatInput      textinput.Model
@@ -93,10 +67,77 @@ type chatModel struct {
 	sessionToken       string
 	user               *pb.User
 	chatStream         pb.ChatService_ChatStreamClient
+	chatWriter         *streamWriter // sends on chatStream
 	msgChan            chan *pb.MessageStream
+	selectedMsg        int
+	reactionPicker     bool
+	reactionPickerIdx  int
+	showReactors       bool
+	suggestions        suggestions
+	activeChat         string         // id of the chat with an open stream
+	seen               map[string]int // number of messages seen in each chat
+	markdown           *markdownRenderer
+	rawMessages        bool // show message content without rendering markdown
+	cfg                Config
+	previews           *imagePreviews
+	sixelPlacements    []sixelPlacement
+	links              []linkPlacement
+	overlays           *overlayState
+	linkPicker         bool
+	linkPickerIdx      int
+	pickerLinks        []chatLink
+	recentEmoji        []string // most recently used first
+	commandErr         string   // error of the last command, shown above the input
+	commandHelp        bool
+	palette            bool
+	paletteInput       textinput.Model
+	paletteIdx         int
+	paletteMatches     paletteItems
+	reselectChat       string // chat to move the cursor back to once the chat list is filtered
+	insertMode         bool   // typing into the focused input in vim mode
+	pendingTop         bool   // the first key of gg was pressed
+	searching          bool
+	searchInput        textinput.Model
+	searchQuery        string
+	layout             layoutPrefs
+	detailsCursor      int
+	presence           map[string]*pb.Presence // by username
+	presenceStream     pb.ChatService_PresenceStreamClient
+	presenceChan       chan *pb.Presence
+	presenceState      pb.PresenceState // the user's, as last published
+	lastInput          time.Time
+	typing             map[string]time.Time // when each person typing in the active chat was last told of
+	typingSent         time.Time
+	pending            []pendingMessage // messages sent to the active chat and not streamed back yet
+	readSent           string           // latest message of the active chat the others were told was read
+	confirmDelete      string           // message that is deleted if the delete key is pressed again
+	droppedInput       string           // input holding the pasted path of a file, uploaded on enter
+	templateFailed     bool             // the message template failed on a message, which was reported
+	session            context.Context  // the requests of the session, cancelled on logging out
+	endSession         context.CancelFunc
 }
 
 func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if s, ok := msg.(sessionMsg); ok {
+		if s.session != m.session {
+			return m, nil
+		}
+		msg = s.msg
+	}
+	model, cmd := m.update(msg)
+	next, ok := model.(chatModel)
+	if !ok {
+		// Logged out
+		return model, cmd
+	}
+	// The open chat is marked read once it is focused and has new messages,
+	// whichever of the two came last
+	cmd = tea.Batch(cmd, next.sendRead(), next.previews.flush())
+	next.trackOverlays(msg)
+	return next, inSession(m.session, cmd)
+}
+
+func (m chatModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var (
 		vCmd        tea.Cmd
 		iCmd        tea.Cmd
@@ -107,6 +148,9 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		sndReqCmd   tea.Cmd
 		joinNameCmd tea.Cmd
 		joinPassCmd tea.Cmd
+		emojiCmd    tea.Cmd
+		paletteCmd  tea.Cmd
+		typingCmd   tea.Cmd
 	)
 
 	if !m.chatsLoading && !m.chatsLoaded {
@@ -118,52 +162,22 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(m.requestsList.StartSpinner(), m.getRequests())
 	}
 
-	switch m.focusedPanel {
-	case MESSAGE_PANEL:
-		m.input.Focus()
-		m.nameChatInput.Blur()
-		m.passkeyChatInput.Blur()
-	case JOIN_ROOM_PANEL:
-		if !m.joinGroupLoading && !m.groupInputDone {
-			if m.joinRoomFocusIndex == 0 {
-				m.nameChatInput.Focus()
-				m.passkeyChatInput.Blur()
-			} else {
-				m.nameChatInput.Blur()
-				m.passkeyChatInput.Focus()
-			}
-		} else {
-			m.nameChatInput.Blur()
-			m.passkeyChatInput.Blur()
-		}
-		m.addUserInput.Blur()
-		m.input.Blur()
-	case SEND_REQUEST_PANNEL:
-		if !m.sendRequestLoading {
-			m.addUserInput.Focus()
-		} else {
-			m.addUserInput.Blur()
+	m.updateFocus()
+	draft := m.input.Value()
+
+	switch msg.(type) {
+	case tea.KeyMsg, tea.MouseMsg:
+		if cmd := m.noteInput(msg); cmd != nil {
+			return m, cmd
 		}
-		m.input.Blur()
-		m.passkeyChatInput.Blur()
-		m.nameChatInput.Blur()
-	default:
-		m.input.Blur()
-		m.addUserInput.Blur()
-		m.passkeyChatInput.Blur()
-		m.nameChatInput.Blur()
 	}
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
-
-		m.help.Width = m.width
-		m.input.SetWidth(m.width - m.chatList.Width() - 4)                 // 4 is to account for the borders
-		m.viewport.Width = m.width - lipgloss.Width(m.chatList.View()) - 7 // 7 seems to work without overflowing
-
-		m.chatList.SetHeight(m.height - m.helpHeight - m.joinRoomHeight - m.sndRequestHeight - m.requestsList.Height() - 7)
-		m.viewport.Height = m.height - lipgloss.Height(m.input.View()) - m.helpHeight - 7
+		m.resize()
+	case tea.MouseMsg:
+		return m, m.updateMouse(msg)
 	case spinner.TickMsg:
 		m.progressIndicator, sCmd = m.progressIndicator.Update(msg)
 		m.chatList, lCmd = m.chatList.Update(msg)
@@ -185,20 +199,107 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			group := msg.sRes.(*pb.ChatResponse)
 			m.msg = successTextStyle.Render("Created group chat: " + *group.Name)
 			return m, tea.Batch(vCmd, iCmd, m.wait(), m.getChats())
+		case STATUS_ATTACHMENT_UPLOAD:
+			res := msg.sRes.(uploadResult)
+			if m.chatStream == nil || res.chatID != m.activeChat {
+				m.msg = "Uploaded " + res.attachment.Name + " after leaving the chat"
+				return m, nil
+			}
+			m.msg = successTextStyle.Render("Uploaded " + res.attachment.Name)
+			return m, m.send(&pb.MessageStream{ChatId: res.chatID, Message: &pb.Message{
+				Sender:     m.user,
+				Content:    res.attachment.Name,
+				SentAt:     timestamppb.Now(),
+				Type:       pb.Message_MESSAGE_TYPE_ATTACHMENT,
+				Attachment: res.attachment,
+			}})
+		case STATUS_PREVIEW_LOAD:
+			m.previews.loaded(msg.sRes.(previewResult))
+			m.renderMessages()
+			return m, m.drawOverlays()
+		case STATUS_OVERLAYS_DRAW:
+			if !m.overlays.dirty {
+				return m, nil
+			}
+			m.overlays.dirty = false
+			seq, _ := m.overlay()
+			return m, writeTerminal(seq)
+		case STATUS_CHAT_EXPORT:
+			m.msg = successTextStyle.Render("Exported to " + msg.sRes.(string))
+		case STATUS_ATTACHMENT_DOWNLOAD:
+			m.msg = successTextStyle.Render("Saved to " + msg.sRes.(string))
+		case STATUS_DRAFT_EDIT:
+			draft := msg.sRes.(string)
+			m.input.SetValue(draft)
+			m.fitInput()
+			if !m.cfg.SendAfterEdit || m.chatStream == nil || strings.TrimSpace(draft) == "" {
+				return m, nil
+			}
+			// Sent as if enter was pressed
+			if isCommand(draft) {
+				return m.runCommand(draft)
+			}
+			m.input.Reset()
+			m.fitInput()
+			return m, m.sendMessage(unescapeCommand(draft))
+		case STATUS_MESSAGE_FAILED:
+			m.failPending(msg.sRes.(*pb.Message))
+			return m, nil
+		case STATUS_TYPING_EXPIRE:
+			m.expireTyping()
+			return m, nil
+		case STATUS_CLOCK_TICK:
+			m.renderMessages()
+			return m, m.clockTick()
+		case STATUS_PRESENCE_OPEN, STATUS_PRESENCE_RECV, STATUS_PRESENCE_CLOSE, STATUS_PRESENCE_TICK:
+			return m, m.updatePresence(msg)
 		case STATUS_REQUEST_ACTION_SEND:
 			m.chatsLoading = false
 			m.chatsLoaded = false
+		case STATUS_GROUP_ACTION_SEND:
+			m.msg = successTextStyle.Render(msg.sRes.(string))
+			m.chatsLoading = false
+			m.chatsLoaded = false
 		case STATUS_CHATS_LOAD:
 			var chats []list.Item
 
-			for _, v := range msg.sRes.([]chatItem) {
+			prevMentions := map[string]int{}
+			prevMessages := map[string][]*pb.Message{}
+			for _, v := range m.chatList.Items() {
+				prevMentions[v.(chatItem).id] = v.(chatItem).mentions
+				prevMessages[v.(chatItem).id] = v.(chatItem).messages
+			}
+
+			loaded := msg.sRes.([]chatItem)
+			for i, v := range loaded {
+				// Messages streamed in after the snapshot was taken are kept
+				loaded[i].messages = mergeMessages(v.messages, messagesAfter(prevMessages[v.id], v.lastActivity()))
+			}
+
+			sortByActivity(loaded)
+			for _, v := range loaded {
+				v.unread, v.mentions = m.countUnread(v)
+				if p, ok := m.presence[v.partner]; ok && v.chatType == pb.ChatType_CHAT_TYPE_DIRECT {
+					v.presence = p.State
+				}
+				if v.mentions > prevMentions[v.id] {
+					m.msg = mentionTextStyle.Render("You were mentioned in " + v.Title())
+				}
 				chats = append(chats, v)
 			}
 			m.chatsLoading = false
 			m.chatsLoaded = true
 
+			// Keep the cursor on the same chat as the order changes. A
+			// filtered list is only updated once the filter runs again.
+			m.reselectChat = m.selectedChatID()
 			m.chatList.StopSpinner()
-			m.chatList.SetItems(chats)
+			lCmd = m.chatList.SetItems(chats)
+			if lCmd == nil {
+				m.selectChat(m.reselectChat)
+				m.reselectChat = ""
+			}
+			return m, lCmd
 		case STATUS_REQUEST_LOAD:
 			var requests []list.Item
 
@@ -216,84 +317,279 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if message == nil {
 				return m, tea.Batch(vCmd, iCmd, m.wait())
 			}
-			m.messages = []string{}
+			i, chat, ok := m.activeChatItem()
 
-			for _, v := range m.chatList.SelectedItem().(chatItem).messages {
-				m.messages = append(m.messages, m.formatMessage(v))
+			if message.Typing != nil {
+				return m, tea.Batch(m.wait(), m.receiveTyping(message.Typing))
+			}
+			if message.Ack != nil {
+				m.ackPending(message.Ack)
+				return m, m.wait()
+			}
+			if message.Read != nil {
+				m.receiveRead(message.Read)
+				return m, m.wait()
+			}
+			if message.Deletion != nil {
+				m.receiveDeletion(message.Deletion)
+				return m, m.wait()
+			}
+			if message.Reaction != nil {
+				if ok {
+					applyReaction(chat.messages, message.Reaction)
+				}
+				m.renderMessages()
+				return m, m.wait()
+			}
+			if message.Message == nil {
+				return m, m.wait()
+			}
+
+			if ok {
+				chat.messages = mergeMessages(chat.messages, []*pb.Message{message.Message})
+				m.chatList.SetItem(i, chat)
+			}
+			if message.Message.Sender != nil {
+				delete(m.typing, message.Message.Sender.Username)
+			}
+			if message.Message.Sender.GetUsername() == m.user.Username {
+				m.resolvePending(message.Message)
+			}
+			if m.focusedPanel != MESSSAGE_VIEW_PANEL {
+				m.selectedMsg = len(chat.messages) - 1
+			}
+			m.renderMessages()
+			if m.focusedPanel != MESSSAGE_VIEW_PANEL {
+				m.viewport.GotoBottom()
 			}
-			m.messages = append(m.messages, m.formatMessage(message.Message))
-			vStr := strings.Join(m.messages, "\n")
-			m.viewport.SetContent(vStr)
-			m.viewport.GotoBottom()
 
 			m.viewport, vCmd = m.viewport.Update(msg)
 			m.input, iCmd = m.input.Update(msg)
 
-			return m, tea.Batch(vCmd, iCmd, m.wait(), m.getChats())
+			return m, tea.Batch(vCmd, iCmd, m.wait(), m.getChats(), m.loadPreviews([]*pb.Message{message.Message}), m.drawOverlays())
 		}
 	case tea.KeyMsg:
-		if msg.String() == tea.KeyCtrlC.String() {
-			if m.chatStream != nil {
-				m.chatStream.CloseSend()
+		if key.Matches(msg, m.keys.Quit) {
+			if m.chatWriter != nil {
+				m.chatWriter.close()
 			}
 			return m, tea.Quit
 		}
-		if msg.String() == "?" {
-			if m.focusedPanel != MESSAGE_PANEL {
+		if m.reactionPicker {
+			return m.updateReactionPicker(msg)
+		}
+		if m.linkPicker {
+			return m.updateLinkPicker(msg)
+		}
+		if m.palette {
+			return m.updatePalette(msg)
+		}
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		if key.Matches(msg, m.keys.Palette) && !m.chatList.SettingFilter() {
+			return m, m.openPalette()
+		}
+		if m.focusedPanel == CHATS_PANEL && m.chatList.SettingFilter() {
+			m.chatList, lCmd = m.chatList.Update(msg)
+			m.chatList.SetShowStatusBar(m.chatList.FilterState() != list.Unfiltered)
+			switch {
+			case key.Matches(msg, m.keys.Enter):
+				chat, ok := m.chatList.SelectedItem().(chatItem)
+				if !ok {
+					return m, lCmd
+				}
+				return m, tea.Batch(lCmd, m.openChat(chat.id))
+			case key.Matches(msg, m.keys.NextPanel, m.keys.PrevPanel, m.keys.Up, m.keys.Down):
+				// The filter is accepted and the key handled as usual
+			default:
+				return m, lCmd
+			}
+		}
+		if m.focusedPanel == MESSAGE_PANEL && m.suggestions.visible() {
+			// Enter runs a command whose name is already typed out
+			if s := m.suggestions.selected(); key.Matches(msg, m.keys.Enter) && strings.TrimSpace(s.value) == wordBeforeCursor(m.input) {
+				m.suggestions = suggestions{}
+			}
+		}
+		if m.focusedPanel == MESSAGE_PANEL && m.suggestions.visible() {
+			switch {
+			case key.Matches(msg, m.keys.Complete, m.keys.Enter):
+				s := m.suggestions.selected()
+				replaceWordBeforeCursor(&m.input, s.value)
+				m.suggestions = suggestions{}
+				if code := shortcodePattern.FindStringSubmatch(s.desc); code != nil {
+					return m, m.useEmoji(code[1])
+				}
+				return m, nil
+			case key.Matches(msg, m.keys.Up):
+				m.suggestions.prev()
+				return m, nil
+			case key.Matches(msg, m.keys.Down):
+				m.suggestions.next()
+				return m, nil
+			case key.Matches(msg, m.keys.Cancel):
+				m.suggestions = suggestions{}
+				return m, nil
+			}
+		}
+		if m.insertMode && m.cfg.InputMode == "vim" && key.Matches(msg, m.keys.Cancel) {
+			m.insertMode = false
+			m.updateFocus()
+			return m, nil
+		}
+		if m.normalMode() {
+			if cmd, ok := m.updateNormalMode(msg); ok {
+				return m, tea.Batch(cmd, m.drawOverlays())
+			}
+		}
+		if key.Matches(msg, m.keys.Help) {
+			if m.focusedPanel != MESSAGE_PANEL || m.normalMode() {
 				m.help.ShowAll = !m.help.ShowAll
+				m.resize()
 			}
 		}
+		focused := m.focusedPanel
 		if !m.joinGroupLoading && !m.sendRequestLoading {
-			switch msg.String() {
-			case "tab":
-				if m.focusedPanel == MAX_PANEL_NO-1 {
-					m.focusedPanel = 0
-				} else {
-					m.focusedPanel++
-				}
-			case "shift+tab":
-				if m.focusedPanel == 0 {
-					m.focusedPanel = MAX_PANEL_NO - 1
-				} else {
-					m.focusedPanel--
+			switch {
+			case key.Matches(msg, m.keys.NextPanel):
+				m.cyclePanels(1)
+			case key.Matches(msg, m.keys.PrevPanel):
+				m.cyclePanels(-1)
+			case key.Matches(msg, m.keys.GrowSidebar, m.keys.ShrinkSidebar):
+				prefs := m.layout
+				prefs.SidebarWidth = m.panes().sidebarWidth + sidebarWidthStep
+				if key.Matches(msg, m.keys.ShrinkSidebar) {
+					prefs.SidebarWidth -= 2 * sidebarWidthStep
 				}
-			case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6":
+				prefs.SidebarWidth = clampSidebarWidth(prefs.SidebarWidth, m.width)
+				return m, m.setLayout(prefs)
+			case key.Matches(msg, m.keys.ToggleSidebar):
+				prefs := m.layout
+				prefs.SidebarCollapsed = !prefs.SidebarCollapsed
+				prefs.Zen = false
+				return m, tea.Batch(m.setLayout(prefs), m.drawOverlays())
+			case key.Matches(msg, m.keys.Zen):
+				prefs := m.layout
+				prefs.Zen = !prefs.Zen
+				return m, tea.Batch(m.setLayout(prefs), m.drawOverlays())
+			case key.Matches(msg, m.keys.ToggleDetails):
+				return m, tea.Batch(m.toggleDetails(), m.drawOverlays())
+			case key.Matches(msg, m.keys.FocusChats, m.keys.FocusReqs, m.keys.FocusSendReq, m.keys.FocusJoin, m.keys.FocusInput, m.keys.FocusView, m.keys.FocusDetails):
 				if m.input.Focused() {
 					m.input.Blur()
 				}
 				var focused int
-				switch msg.String() {
-				case "alt+1":
+				switch {
+				case key.Matches(msg, m.keys.FocusChats):
 					focused = CHATS_PANEL
-				case "alt+2":
+				case key.Matches(msg, m.keys.FocusReqs):
 					focused = ACTIVE_REQUEST_PANEL
-				case "alt+3":
+				case key.Matches(msg, m.keys.FocusSendReq):
 					focused = SEND_REQUEST_PANNEL
-				case "alt+4":
+				case key.Matches(msg, m.keys.FocusJoin):
 					focused = JOIN_ROOM_PANEL
-				case "alt+5":
+				case key.Matches(msg, m.keys.FocusInput):
 					focused = MESSAGE_PANEL
-				case "alt+6":
+				case key.Matches(msg, m.keys.FocusView):
 					focused = MESSSAGE_VIEW_PANEL
+				case key.Matches(msg, m.keys.FocusDetails):
+					focused = DETAILS_PANEL
+				}
+				if m.panelVisible(focused) {
+					m.focusedPanel = focused
 				}
-				m.focusedPanel = focused
 			default:
-				switch msg.String() {
-				case "ctrl+a":
+				switch {
+				case key.Matches(msg, m.keys.Accept):
 					if m.focusedPanel == ACTIVE_REQUEST_PANEL {
 						req := m.requestsList.SelectedItem().(requestItem)
 						m.requestsList.RemoveItem(m.requestsList.Index())
 						m.requestsList, rCmd = m.requestsList.Update(msg)
 						return m, tea.Batch(rCmd, m.sendRequestAction(req.id, pb.DirectChatAction_ACTION_ACCEPT))
 					}
-				case "ctrl+x":
+				case key.Matches(msg, m.keys.Reject):
 					if m.focusedPanel == ACTIVE_REQUEST_PANEL {
 						req := m.requestsList.SelectedItem().(requestItem)
 						m.requestsList.RemoveItem(m.requestsList.Index())
 						m.requestsList, rCmd = m.requestsList.Update(msg)
 						return m, tea.Batch(rCmd, m.sendRequestAction(req.id, pb.DirectChatAction_ACTION_REJECT))
 					}
-				case "left":
+				case key.Matches(msg, m.keys.React):
+					if m.focusedPanel == MESSSAGE_VIEW_PANEL {
+						msgs := m.activeMessages()
+						if m.selectedMsg >= len(msgs) {
+							break
+						}
+						if msgs[m.selectedMsg].Id == "" || msgs[m.selectedMsg].Type == pb.Message_MESSAGE_TYPE_NOTIFICATION {
+							m.msg = "Cannot react to this message"
+							break
+						}
+						m.reactionPicker = true
+						m.reactionPickerIdx = 0
+						m.showReactors = false
+						m.renderMessages()
+					}
+				case key.Matches(msg, m.keys.Reactors):
+					if m.focusedPanel == MESSSAGE_VIEW_PANEL {
+						m.showReactors = !m.showReactors
+						m.renderMessages()
+					}
+				case key.Matches(msg, m.keys.Download):
+					if m.focusedPanel == MESSSAGE_VIEW_PANEL {
+						msgs := m.activeMessages()
+						if m.selectedMsg >= len(msgs) || msgs[m.selectedMsg].Attachment == nil {
+							break
+						}
+						att := msgs[m.selectedMsg].Attachment
+						m.msg = notificationTextStyle.Render("Downloading " + att.Name)
+						return m, m.downloadAttachment(att, m.cfg.downloadDir())
+					}
+				case key.Matches(msg, m.keys.Links):
+					m.openLinkPicker()
+					return m, nil
+				case key.Matches(msg, m.keys.Delete):
+					if m.focusedPanel == MESSSAGE_VIEW_PANEL {
+						return m, m.deleteSelectedMessage()
+					}
+				case key.Matches(msg, m.keys.Export):
+					if m.focusedPanel == MESSSAGE_VIEW_PANEL {
+						_, chat, ok := m.activeChatItem()
+						if !ok {
+							break
+						}
+						return m, m.exportChat(chat, m.cfg.downloadDir())
+					}
+				case key.Matches(msg, m.keys.Search):
+					if m.focusedPanel == MESSSAGE_VIEW_PANEL {
+						return m, m.openSearch()
+					}
+				case key.Matches(msg, m.keys.NextMatch):
+					if m.focusedPanel == MESSSAGE_VIEW_PANEL {
+						m.findMessage(1)
+					}
+				case key.Matches(msg, m.keys.PrevMatch):
+					if m.focusedPanel == MESSSAGE_VIEW_PANEL {
+						m.findMessage(-1)
+					}
+				case key.Matches(msg, m.keys.Cancel):
+					if m.focusedPanel == MESSAGE_PANEL && m.commandHelp {
+						m.commandHelp = false
+						return m, m.drawOverlays()
+					}
+				case key.Matches(msg, m.keys.Editor):
+					if m.focusedPanel == MESSAGE_PANEL {
+						return m, editDraft(m.input.Value())
+					}
+				case key.Matches(msg, m.keys.RawToggle):
+					if m.focusedPanel == MESSSAGE_VIEW_PANEL {
+						m.rawMessages = !m.rawMessages
+						m.renderMessages()
+					}
+				case key.Matches(msg, m.keys.Left):
+					if m.focusedPanel == CHATS_PANEL && m.panes().switcher {
+						m.moveSelection(true)
+					}
 					// cycle between button options
 					if m.focusedPanel == JOIN_ROOM_PANEL && m.groupInputDone {
 						if m.groupFocusedBtn == 0 {
@@ -304,7 +600,10 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						}
 
 					}
-				case "right":
+				case key.Matches(msg, m.keys.Right):
+					if m.focusedPanel == CHATS_PANEL && m.panes().switcher {
+						m.moveSelection(false)
+					}
 					if m.focusedPanel == JOIN_ROOM_PANEL && m.groupInputDone {
 						if m.groupFocusedBtn == 1 {
 
@@ -313,7 +612,7 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							m.groupFocusedBtn = 1
 						}
 					}
-				case "up":
+				case key.Matches(msg, m.keys.Up):
 					switch m.focusedPanel {
 					case JOIN_ROOM_PANEL:
 						if m.joinRoomFocusIndex == 0 {
@@ -326,14 +625,10 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.nameChatInput, joinNameCmd = m.nameChatInput.Update(msg)
 						m.passkeyChatInput, joinPassCmd = m.passkeyChatInput.Update(msg)
 						return m, tea.Batch(joinNameCmd, joinPassCmd)
-					case MESSSAGE_VIEW_PANEL:
-						m.viewport.HalfViewUp()
-					case CHATS_PANEL:
-						m.chatList.CursorUp()
-					case ACTIVE_REQUEST_PANEL:
-						m.requestsList.CursorUp()
+					default:
+						m.moveSelection(true)
 					}
-				case "down":
+				case key.Matches(msg, m.keys.Down):
 					switch m.focusedPanel {
 					case JOIN_ROOM_PANEL:
 						if m.joinRoomFocusIndex == 0 {
@@ -346,14 +641,10 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.nameChatInput, joinNameCmd = m.nameChatInput.Update(msg)
 						m.passkeyChatInput, joinPassCmd = m.passkeyChatInput.Update(msg)
 						return m, tea.Batch(joinNameCmd, joinPassCmd)
-					case MESSSAGE_VIEW_PANEL:
-						m.viewport.HalfViewDown()
-					case CHATS_PANEL:
-						m.chatList.CursorDown()
-					case ACTIVE_REQUEST_PANEL:
-						m.requestsList.CursorDown()
+					default:
+						m.moveSelection(false)
 					}
-				case "enter":
+				case key.Matches(msg, m.keys.Enter):
 					switch m.focusedPanel {
 					case JOIN_ROOM_PANEL:
 						if !m.groupInputDone {
@@ -363,24 +654,10 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 								m.groupInputDone = true
 							}
 						} else {
-							name, passkey := m.nameChatInput.Value(), m.passkeyChatInput.Value()
-							m.chatList.Select(len(m.chatList.Items()) - 1)
-							m.passkeyChatInput.Reset()
-							m.nameChatInput.Reset()
-							m.joinRoomFocusIndex = 0
-							m.joinGroupLoading = true
-							m.nameChatInput.Blur()
-							m.passkeyChatInput.Blur()
-
-							switch m.groupFocusedBtn {
-							case CREATE_GROUP_BTN:
-								m.groupInputDone = false
-								return m, tea.Batch(joinNameCmd, joinPassCmd, m.progressIndicator.Tick, m.sendGroupChatCreateRequest(name, passkey))
-							case JOIN_GROUP_BTN:
-								m.groupInputDone = false
-								return m, tea.Batch(joinNameCmd, joinPassCmd, m.progressIndicator.Tick, m.sendGroupChatJoinRequest(name, passkey))
-							}
+							return m, tea.Batch(joinNameCmd, joinPassCmd, m.submitJoinRoom())
 						}
+					case DETAILS_PANEL:
+						return m, m.messageMember()
 					case SEND_REQUEST_PANNEL:
 						receiver := m.addUserInput.Value()
 						m.addUserInput.Reset()
@@ -388,48 +665,53 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.sendRequestLoading = true
 						return m, tea.Batch(sndReqCmd, m.progressIndicator.Tick, m.sendDirectChatJoinRequest(receiver))
 					case MESSAGE_PANEL:
+						msgContent := m.input.Value()
+						if strings.TrimSpace(msgContent) == "" {
+							return m, nil
+						}
+						// Dropped files are pasted as their path, which may
+						// look like a command. Only a path that was pasted
+						// is uploaded, so typing a file name sends it as text.
+						if path, ok := parseAttachmentPath(msgContent); ok && msgContent == m.droppedInput && m.chatStream != nil {
+							m.droppedInput = ""
+							m.input.Reset()
+							m.fitInput()
+							m.msg = notificationTextStyle.Render("Uploading " + filepath.Base(path))
+							return m, m.uploadAttachment(m.activeChat, path)
+						}
+						if isCommand(msgContent) {
+							return m.runCommand(msgContent)
+						}
 						if m.chatStream != nil {
-
-							chat := m.chatList.SelectedItem().(chatItem)
-
-							m.viewport.SetContent(strings.Join(m.messages, "\n"))
-							msgContent := m.input.Value()
+							m.commandHelp = false
 							m.input.Reset()
 							m.input, iCmd = m.input.Update(msg)
+							m.fitInput()
 
-							return m, tea.Batch(iCmd,
-								m.send(&pb.MessageStream{ChatId: chat.id, Message: &pb.Message{
-									Sender:  m.user,
-									Content: msgContent,
-									SentAt:  timestamppb.Now(),
-									Type:    pb.Message_MESSAGE_TYPE_REGULAR,
-								}}))
+							return m, tea.Batch(iCmd, m.sendMessage(unescapeCommand(msgContent)))
 						}
 					case CHATS_PANEL:
-						if m.chatStream != nil {
-							m.chatStream.CloseSend()
+						chat, ok := m.chatList.SelectedItem().(chatItem)
+						if !ok {
+							break
 						}
-
-						m.msgChan = make(chan *pb.MessageStream)
-
-						chat := m.chatList.SelectedItem().(chatItem)
-
-						m.viewport.GotoBottom()
+						cmd := m.openChat(chat.id)
 						m.viewport, vCmd = m.viewport.Update(msg)
 						m.input, iCmd = m.input.Update(msg)
-						m.focusedPanel = MESSAGE_PANEL
-
-						err := m.initializeStream(chat.id)
 
-						if err != nil {
-							return m, tea.Quit
-						}
-
-						return m, tea.Batch(vCmd, iCmd, lCmd, m.recv(), m.wait())
+						return m, tea.Batch(vCmd, iCmd, lCmd, cmd)
 					}
 				}
 			}
 		}
+		if m.focusedPanel != focused {
+			if !isInputPanel(m.focusedPanel) {
+				m.insertMode = false
+			}
+			// Show or hide the message selection
+			m.showReactors = false
+			m.renderMessages()
+		}
 	case errMsg:
 		if msg.err == io.EOF {
 			return m, m.getChats()
@@ -440,15 +722,50 @@ func (m chatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 	m.viewport, vCmd = m.viewport.Update(msg)
 	m.input, iCmd = m.input.Update(msg)
-	m.chatList, lCmd = m.chatList.Update(msg)
+	if _, ok := msg.(tea.KeyMsg); !ok || m.focusedPanel == CHATS_PANEL {
+		m.chatList, lCmd = m.chatList.Update(msg)
+		m.chatList.SetShowStatusBar(m.chatList.FilterState() != list.Unfiltered)
+	}
+	if _, ok := msg.(list.FilterMatchesMsg); ok && m.reselectChat != "" {
+		m.selectChat(m.reselectChat)
+		m.reselectChat = ""
+	}
 	m.help, hCmd = m.help.Update(msg)
 	m.progressIndicator, sCmd = m.progressIndicator.Update(msg)
 	m.addUserInput, sndReqCmd = m.addUserInput.Update(msg)
 	m.nameChatInput, joinNameCmd = m.nameChatInput.Update(msg)
 	m.passkeyChatInput, joinPassCmd = m.passkeyChatInput.Update(msg)
 	m.requestsList, rCmd = m.requestsList.Update(msg)
+	if m.palette {
+		m.paletteInput, paletteCmd = m.paletteInput.Update(msg)
+	}
 
-	return m, tea.Batch(iCmd, vCmd, lCmd, hCmd, joinPassCmd, joinNameCmd, sndReqCmd, sCmd, rCmd)
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		m.suggestions = suggestions{}
+		if m.focusedPanel == MESSAGE_PANEL && !m.normalMode() {
+			m.commandErr = ""
+			// A :shortcode: is expanded once its closing colon is typed or
+			// pasted
+			if len(m.input.Value()) > len(draft) {
+				emojiCmd = m.expandShortcodeBeforeCursor()
+			}
+			m.suggestions.items = m.suggest(wordBeforeCursor(m.input))
+			m.fitInput()
+			if m.input.Value() != draft {
+				typingCmd = m.sendTyping()
+			}
+
+			// Files dropped into the terminal are pasted as their path
+			if _, ok := parseAttachmentPath(m.input.Value()); ok && msg.Paste {
+				m.droppedInput = m.input.Value()
+				m.msg = notificationTextStyle.Render("Press " + m.keys.Enter.Help().Key + " to upload the dropped file")
+			} else if m.input.Value() != m.droppedInput {
+				m.droppedInput = ""
+			}
+		}
+	}
+
+	return m, tea.Batch(iCmd, vCmd, lCmd, hCmd, joinPassCmd, joinNameCmd, sndReqCmd, sCmd, rCmd, emojiCmd, paletteCmd, typingCmd, m.drawOverlays())
 }
 
 func (m chatModel) View() string {
@@ -458,6 +775,7 @@ func (m chatModel) View() string {
 	requestView := unfocusedBorderStyle
 	sendRequestView := unfocusedBorderStyle
 	joinRoomView := unfocusedBorderStyle
+	detailsView := unfocusedBorderStyle
 
 	var joinPlaceholder string
 	var sendRequestPlaceholder string
@@ -483,6 +801,33 @@ func (m chatModel) View() string {
 		joinPlaceholder = lipgloss.JoinHorizontal(lipgloss.Center, createBtn.Render("[CREATE]"), "    ", joinBtn.Render("[JOIN]"))
 	}
 
+	// Suggestions are shown above the input, taking space from the viewport
+	vp := m.shownViewport()
+	input := m.input.View()
+	if m.suggestions.visible() {
+		input = m.suggestions.View() + "\n" + input
+	}
+	if m.commandErr != "" {
+		input = errorTextStyle.Render(m.commandErr) + "\n" + input
+	}
+	if len(m.typing) > 0 {
+		input = m.formatTyping(m.input.Width()) + "\n" + input
+	}
+	messages := vp.View()
+	switch {
+	case m.palette:
+		messages = m.formatPalette(vp.Width, vp.Height)
+	case m.linkPicker:
+		messages = m.formatLinkPicker(vp.Width, vp.Height)
+	case m.commandHelp:
+		messages = m.formatCommandHelp(vp.Width, vp.Height)
+	}
+
+	status := m.formatMode() + errorTextStyle.Render(m.msg)
+	if m.searching {
+		status = m.searchInput.View()
+	}
+
 	switch m.focusedPanel {
 	case CHATS_PANEL:
 		listView = focusedBorderStyle
@@ -496,85 +841,70 @@ func (m chatModel) View() string {
 		sendRequestView = focusedBorderStyle
 	case JOIN_ROOM_PANEL:
 		joinRoomView = focusedBorderStyle
+	case DETAILS_PANEL:
+		detailsView = focusedBorderStyle
 	}
 
-	return lipgloss.JoinVertical(
-		lipgloss.Top, lipgloss.JoinHorizontal(
-			lipgloss.Left,
-			lipgloss.JoinVertical(
-				lipgloss.Top,
-				listView.Render(m.chatList.View()),
-				requestView.Render(m.requestsList.View()),
-				sendRequestView.Render(
-					fmt.Sprintf(
-						"Send a chat request\n%s\n%s",
-						m.addUserInput.View(),
-						sendRequestPlaceholder,
-					),
-				),
-				joinRoomView.Render(
-					fmt.Sprintf(
-						"Join a room\n%s\n%s\n%s",
-						m.nameChatInput.View(),
-						m.passkeyChatInput.View(),
-						joinPlaceholder,
-					),
+	p := m.panes()
+	var sidebar []string
+	if p.sidebar {
+		sidebar = append(sidebar, listView.Copy().Width(p.sidebarWidth).Render(m.chatList.View()))
+	}
+	if p.sidePanels {
+		sidebar = append(sidebar,
+			requestView.Copy().Width(p.sidebarWidth).Render(m.requestsList.View()),
+			sendRequestView.Copy().Width(p.sidebarWidth).Render(
+				fmt.Sprintf(
+					"Send a chat request\n%s\n%s",
+					m.addUserInput.View(),
+					sendRequestPlaceholder,
 				),
 			),
-			lipgloss.JoinVertical(
-				lipgloss.Top,
-				chatView.Render(m.viewport.View()),
-				inputView.Render(m.input.View()),
+			joinRoomView.Copy().Width(p.sidebarWidth).Render(
+				fmt.Sprintf(
+					"Join a room\n%s\n%s\n%s",
+					m.nameChatInput.View(),
+					m.passkeyChatInput.View(),
+					joinPlaceholder,
+				),
 			),
-		),
-		errorTextStyle.Render(m.msg),
-		m.help.View(m.keys),
-	)
+		)
+	}
+
+	var chat []string
+	if p.switcher {
+		chat = append(chat, " "+m.formatChatSwitcher(p.chatWidth))
+	}
+	chat = append(chat, chatView.Render(messages), inputView.Render(input))
+
+	columns := []string{
+		lipgloss.JoinVertical(lipgloss.Top, sidebar...),
+		lipgloss.JoinVertical(lipgloss.Top, chat...),
+	}
+	if p.details {
+		height := m.bodyHeight() - 2
+		columns = append(columns, detailsView.Copy().Width(detailsWidth).Height(height).Render(
+			m.formatChatDetails(detailsWidth, height),
+		))
+	}
+
+	view := []string{
+		lipgloss.JoinHorizontal(lipgloss.Left, columns...),
+		status,
+	}
+	if p.help {
+		view = append(view, m.help.View(m.keys))
+	}
+	return lipgloss.JoinVertical(lipgloss.Top, view...)
 }
 
 func (m chatModel) Init() tea.Cmd {
-	return nil
+	return inSession(m.session, tea.Batch(m.clockTick(), m.openPresence()))
 }
 
-func NewChatModel(client pb.ChatServiceClient, w, h int, auth *pb.UserAuthenticatedResponse) chatModel {
-	var keys = keyMap{
-		Up: key.NewBinding(
-			key.WithKeys("up"),
-			key.WithHelp("↑", "move up  "),
-		),
-		Down: key.NewBinding(
-			key.WithKeys("down"),
-			key.WithHelp("↓", "move down  "),
-		),
-		Tab: key.NewBinding(
-			key.WithKeys("󰌥/󰌒"),
-			key.WithHelp("󰌥/󰌒", "cycle panels  "),
-		),
-		Help: key.NewBinding(
-			key.WithKeys("?"),
-			key.WithHelp("?", "toggle help  "),
-		),
-		Quit: key.NewBinding(
-			key.WithKeys("ctrl+c"),
-			key.WithHelp("ctrl+c", "quit  "),
-		),
-		Enter: key.NewBinding(
-			key.WithKeys("󰌑"),
-			key.WithHelp("󰌑", "enter room/send message  "),
-		),
-		Accept: key.NewBinding(
-			key.WithKeys("ctrl+a"),
-			key.WithHelp("ctrl+a", "accept request  "),
-		),
-		Reject: key.NewBinding(
-			key.WithKeys("ctrl+x"),
-			key.WithHelp("ctrl+x", "reject request  "),
-		),
-		SwitchPanel: key.NewBinding(
-			key.WithKeys("alt+[n]"),
-			key.WithHelp("alt+[n]", "switch panel (1|chats 2|requests 3|send request 4|join room 5|chat input 6|chat view)  "),
-		),
-	}
+func NewChatModel(client pb.ChatServiceClient, w, h int, auth *pb.UserAuthenticatedResponse, cfg Config) chatModel {
+	keys := newKeyMap(cfg)
+	session, endSession := context.WithCancel(context.Background())
 
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
@@ -582,24 +912,17 @@ func NewChatModel(client pb.ChatServiceClient, w, h int, auth *pb.UserAuthentica
 	joinNameInput := textinput.New()
 	joinNameInput.Placeholder = "Chatroom Name"
 	joinNameInput.Blur()
-	joinNameInput.Width = 28
 
 	joinPasskeyInput := textinput.New()
 	joinPasskeyInput.Placeholder = "Chatroom Passkey"
 	joinNameInput.Blur()
-	joinPasskeyInput.Width = 28
 
 	sendRequestInput := textinput.New()
 	sendRequestInput.Placeholder = "Username"
 	sendRequestInput.Blur()
-	sendRequestInput.Width = 28
-
-	sndReqPanelHeight := 5
-	joinRoomPanelHeight := 6
 
 	hp := help.New()
 	hp.Styles = helpStyle
-	hpHeight := strings.Count(hp.View(keys), "\n")
 
 	reqLt := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
 	reqLt.InfiniteScrolling = true
@@ -608,7 +931,6 @@ func NewChatModel(client pb.ChatServiceClient, w, h int, auth *pb.UserAuthentica
 	reqLt.SetShowStatusBar(false)
 	reqLt.SetShowHelp(false)
 	reqLt.SetFilteringEnabled(false)
-	reqLt.SetSize(31, 8)
 	reqLt.KeyMap = list.KeyMap{}
 	reqLt.SetSpinner(spinner.Dot)
 
@@ -618,9 +940,9 @@ func NewChatModel(client pb.ChatServiceClient, w, h int, auth *pb.UserAuthentica
 	lt.SetShowPagination(true)
 	lt.SetShowStatusBar(false)
 	lt.SetShowHelp(false)
-	lt.SetFilteringEnabled(false)
-	lt.SetSize(31, h-hpHeight-joinRoomPanelHeight-sndReqPanelHeight-reqLt.Height()-7)
-	lt.KeyMap = list.KeyMap{}
+	lt.SetFilteringEnabled(true)
+	lt.Filter = list.UnsortedFilter // chats stay ordered by activity
+	lt.KeyMap = keys.chatListKeyMap()
 	lt.SetSpinner(spinner.Dot)
 
 	ta := textarea.New()
@@ -628,14 +950,14 @@ func NewChatModel(client pb.ChatServiceClient, w, h int, auth *pb.UserAuthentica
 	ta.Focus()
 	ta.Reset()
 	ta.Prompt = "┃ "
+	ta.MaxHeight = cfg.InputMaxHeight
 	ta.SetHeight(1)
 	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
 	ta.Blur()
 	ta.ShowLineNumbers = false
-	ta.KeyMap.InsertNewline.SetEnabled(false)
-	ta.SetWidth(w - lt.Width() - 4)
+	ta.KeyMap.InsertNewline = keys.NewLine
 
-	vp := viewport.New(w-lt.Width()-4, h-hpHeight-8)
+	vp := viewport.New(0, 0)
 	// disable movements
 	vp.KeyMap.Down.SetEnabled(false)
 	vp.KeyMap.Up.SetEnabled(false)
@@ -643,48 +965,145 @@ func NewChatModel(client pb.ChatServiceClient, w, h int, auth *pb.UserAuthentica
 	vp.KeyMap.PageDown.SetEnabled(false)
 	vp.KeyMap.HalfPageUp.SetEnabled(false)
 	vp.KeyMap.HalfPageDown.SetEnabled(false)
+	// The wheel is handled by the chat model, which knows what is under it
+	vp.MouseWheelEnabled = false
 
 	m := chatModel{
 		user:              auth.User,
 		input:             ta,
 		viewport:          vp,
-		messages:          []string{},
 		width:             w,
 		height:            h,
 		chatList:          lt,
 		keys:              keys,
 		requestsList:      reqLt,
-		helpHeight:        hpHeight,
 		loadingMsg:        true,
 		nameChatInput:     joinNameInput,
 		passkeyChatInput:  joinPasskeyInput,
 		addUserInput:      sendRequestInput,
-		sndRequestHeight:  sndReqPanelHeight,
-		joinRoomHeight:    joinRoomPanelHeight,
 		progressIndicator: sp,
 		sessionToken:      auth.Token,
 		client:            client,
+		seen:              map[string]int{},
+		presence:          map[string]*pb.Presence{},
+		typing:            map[string]time.Time{},
+		markdown:          newMarkdownRenderer(),
+		previews:          newImagePreviews(cfg.ImagePreviews),
+		overlays:          &overlayState{},
+		recentEmoji:       loadRecentEmoji(),
+		paletteInput:      newPaletteInput(),
+		searchInput:       newSearchInput(),
+		cfg:               cfg,
+		layout:            loadLayoutPrefs(),
+		session:           session,
+		endSession:        endSession,
+		presenceChan:      make(chan *pb.Presence),
+		lastInput:         time.Now(),
 	}
+	m.resize()
 
 	return m
 }
 
-func enterChat(client pb.ChatServiceClient, w, h int, auth *pb.UserAuthenticatedResponse) (chatModel, tea.Cmd) {
+func enterChat(client pb.ChatServiceClient, w, h int, auth *pb.UserAuthenticatedResponse, cfg Config) (chatModel, tea.Cmd) {
 	altScrCmd := tea.EnterAltScreen
-	return NewChatModel(client, w, h, auth), altScrCmd
+	if cfg.Mouse {
+		altScrCmd = tea.Batch(altScrCmd, tea.EnableMouseCellMotion)
+	}
+	// bubbletea only initializes the model it starts with
+	m := NewChatModel(client, w, h, auth, cfg)
+	return m, tea.Batch(altScrCmd, m.Init())
+}
+
+// Creates or joins the room typed into the join room panel, depending on
+// the focused button
+func (m *chatModel) submitJoinRoom() tea.Cmd {
+	name, passkey := m.nameChatInput.Value(), m.passkeyChatInput.Value()
+	m.chatList.Select(len(m.chatList.Items()) - 1)
+	m.passkeyChatInput.Reset()
+	m.nameChatInput.Reset()
+	m.joinRoomFocusIndex = 0
+	m.joinGroupLoading = true
+	m.groupInputDone = false
+	m.nameChatInput.Blur()
+	m.passkeyChatInput.Blur()
+
+	if m.groupFocusedBtn == CREATE_GROUP_BTN {
+		return tea.Batch(m.progressIndicator.Tick, m.sendGroupChatCreateRequest(name, passkey))
+	}
+	return tea.Batch(m.progressIndicator.Tick, m.sendGroupChatJoinRequest(name, passkey))
 }
 
-func (m chatModel) formatMessage(msg *pb.Message) string {
-	var fmtMsg string
-	switch msg.Type {
-	case pb.Message_MESSAGE_TYPE_REGULAR:
-		snder := "Me"
-		if msg.Sender.Username != m.user.Username {
-			snder = msg.Sender.Username
+// Focuses the input of the focused panel. In vim normal mode no input is
+// focused so keys are not typed into it.
+func (m *chatModel) updateFocus() {
+	switch {
+	case m.normalMode():
+		m.input.Blur()
+		m.addUserInput.Blur()
+		m.passkeyChatInput.Blur()
+		m.nameChatInput.Blur()
+	case m.focusedPanel == MESSAGE_PANEL:
+		m.input.Focus()
+		m.nameChatInput.Blur()
+		m.passkeyChatInput.Blur()
+	case m.focusedPanel == JOIN_ROOM_PANEL:
+		if !m.joinGroupLoading && !m.groupInputDone {
+			if m.joinRoomFocusIndex == 0 {
+				m.nameChatInput.Focus()
+				m.passkeyChatInput.Blur()
+			} else {
+				m.nameChatInput.Blur()
+				m.passkeyChatInput.Focus()
+			}
+		} else {
+			m.nameChatInput.Blur()
+			m.passkeyChatInput.Blur()
+		}
+		m.addUserInput.Blur()
+		m.input.Blur()
+	case m.focusedPanel == SEND_REQUEST_PANNEL:
+		if !m.sendRequestLoading {
+			m.addUserInput.Focus()
+		} else {
+			m.addUserInput.Blur()
 		}
-		fmtMsg = fmt.Sprintf("%s: %s", snder, msg.Content)
-	case pb.Message_MESSAGE_TYPE_NOTIFICATION:
-		fmtMsg = lipgloss.PlaceHorizontal(
+		m.input.Blur()
+		m.passkeyChatInput.Blur()
+		m.nameChatInput.Blur()
+	default:
+		m.input.Blur()
+		m.addUserInput.Blur()
+		m.passkeyChatInput.Blur()
+		m.nameChatInput.Blur()
+	}
+}
+
+// Grows the message input with its content, up to the configured max height.
+// The viewport shrinks to make room for it.
+func (m *chatModel) fitInput() {
+	h := min(inputRows(m.input.Value(), m.input.Width()), m.cfg.InputMaxHeight)
+	if h == m.input.Height() {
+		return
+	}
+	m.input.SetHeight(h)
+	m.resize()
+}
+
+// A message rendered with the message template
+type renderedMessage struct {
+	block   string
+	content string // the rendered content of a regular message
+	line    int    // the line of block the content starts on
+	col     int    // the column the content starts at
+	err     error  // why the message template failed on the message
+}
+
+// Renders a message with the message template, followed by its receipt if
+// it has one.
+func (m chatModel) formatMessage(msg *pb.Message, grouped bool, receipt string, chat chatData) renderedMessage {
+	if msg.Type == pb.Message_MESSAGE_TYPE_NOTIFICATION {
+		return renderedMessage{block: lipgloss.PlaceHorizontal(
 			lipgloss.Width(
 				m.viewport.View(),
 			),
@@ -692,21 +1111,359 @@ func (m chatModel) formatMessage(msg *pb.Message) string {
 			notificationTextStyle.Render(
 				msg.Content,
 			),
-		)
+		)}
+	}
+
+	l, err := m.layoutMessage(msg, grouped, chat)
+	if receipt != "" {
+		l.suffix += " " + receipt
+	}
+	// 1 is to leave room for the selected message border
+	width := max(1, m.viewport.Width-lipgloss.Width(l.prefix)-lipgloss.Width(l.suffix)-1)
+
+	var content string
+	switch {
+	case msg.Type == pb.Message_MESSAGE_TYPE_ATTACHMENT:
+		content = m.formatAttachment(msg.Attachment)
+		if preview := m.previews.render(msg.Attachment.GetId(), width); preview != "" {
+			content = lipgloss.JoinVertical(lipgloss.Left, content, preview)
+		}
+	case m.rawMessages:
+		content = defaultStyle.Copy().Width(width).Render(msg.Content)
+	default:
+		content = highlightMentions(m.markdown.render(msg.Content, width), m.user.Username)
+	}
+
+	lines := append([]string{}, l.above...)
+	lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, l.prefix, content, l.suffix))
+	lines = append(lines, l.below...)
+	r := renderedMessage{block: strings.Join(lines, "\n"), line: len(l.above), col: lipgloss.Width(l.prefix), err: err}
+	if msg.Type == pb.Message_MESSAGE_TYPE_REGULAR {
+		r.content = content
+	}
+	return r
+}
+
+// Returns the viewport as shown, less the lines taken by the composer. The
+// latest messages stay in view if they were.
+func (m chatModel) shownViewport() viewport.Model {
+	vp := m.viewport
+	atBottom := vp.AtBottom()
+	vp.Height -= m.composerHeight()
+	if atBottom {
+		vp.GotoBottom()
+	}
+	return vp
+}
+
+// Number of lines shown above the message input, taken from the viewport
+func (m chatModel) composerHeight() int {
+	h := m.suggestions.height()
+	if m.commandErr != "" {
+		h++
+	}
+	if len(m.typing) > 0 {
+		h++
+	}
+	return h
+}
+
+// Returns the completions for the word being typed in the message input
+func (m chatModel) suggest(word string) []suggestion {
+	if isCommand(m.input.Value()) && m.input.Line() == 0 {
+		if items := m.commandSuggestions(word); items != nil {
+			return items
+		}
+	}
+	switch {
+	case strings.HasPrefix(word, "@"):
+		return m.mentionSuggestions(word)
+	case strings.HasPrefix(word, ":"):
+		return m.emojiSuggestions(word)
+	}
+	return nil
+}
+
+// Returns the messages of the open chat
+func (m chatModel) activeMessages() []*pb.Message {
+	_, chat, ok := m.activeChatItem()
+	if !ok {
+		return nil
+	}
+	return chat.messages
+}
+
+// Renders the messages of the active chat into the viewport. While the message
+// view is focused the selected message is highlighted and kept in view.
+func (m *chatModel) renderMessages() {
+	msgs := m.activeMessages()
+	if m.selectedMsg >= len(msgs) {
+		m.selectedMsg = len(msgs) - 1
+	}
+	if m.selectedMsg < 0 {
+		m.selectedMsg = 0
+	}
+
+	_, chat, ok := m.activeChatItem()
+	positions := m.readPositions(chat)
+	var data chatData
+	if ok {
+		data = newChatData(chat)
+	}
+	var templateErr error
+
+	var blocks []string
+	var selectedStart, selectedHeight, lines int
+	m.sixelPlacements = nil
+	m.links = nil
+	// Links are only looked for in the content of messages, not in what the
+	// template adds around it
+	placeMessageLinks := func(msg *pb.Message, r renderedMessage, line, col int) {
+		for _, pl := range placeLinks(r.content, chatLinks([]*pb.Message{msg}, m.cfg.Linkifiers), m.cfg.Linkifiers) {
+			pl.line += line + r.line
+			pl.col += col + r.col
+			m.links = append(m.links, pl)
+		}
+	}
+	for i, v := range msgs {
+		var prev *pb.Message
+		if i > 0 {
+			prev = msgs[i-1]
+		}
+		if sep := m.formatDaySeparator(prev, v); sep != "" {
+			blocks = append(blocks, sep)
+			lines += lipgloss.Height(sep)
+		}
 
+		var receipt string
+		if v.Sender.GetUsername() == m.user.Username {
+			receipt = m.formatReceipt(chat, positions, i)
+		}
+		r := m.formatMessage(v, m.cfg.GroupMessages && continuesGroup(prev, v), receipt, data)
+		if r.err != nil {
+			templateErr = r.err
+		}
+		block := r.block
+		// The selected message is drawn with a border on its left
+		borderCol := 0
+		if i == m.selectedMsg && m.focusedPanel == MESSSAGE_VIEW_PANEL {
+			borderCol = 1
+		}
+		if m.previews.protocol == IMAGE_PROTOCOL_SIXEL && v.Attachment != nil && m.previews.ready(v.Attachment.Id) {
+			// Previews start on the line after the attachment name
+			pl := sixelPlacement{id: v.Attachment.Id, line: lines + r.line + 1, col: r.col + borderCol}
+			m.sixelPlacements = append(m.sixelPlacements, pl)
+		}
+		placeMessageLinks(v, r, lines, borderCol)
+		if reactions := m.formatReactions(v); reactions != "" {
+			block += "\n" + reactions
+		}
+		if chat.chatType == pb.ChatType_CHAT_TYPE_GROUP {
+			if readers := m.formatReaders(positions, i); readers != "" {
+				block += "\n" + readers
+			}
+		}
+		if i == m.selectedMsg && m.focusedPanel == MESSSAGE_VIEW_PANEL {
+			if m.reactionPicker {
+				block += "\n" + m.formatReactionPicker(v)
+			}
+			if m.showReactors {
+				block += "\n" + m.formatReactors(v)
+			}
+			if previews := m.formatLinkPreviews(v); previews != "" {
+				block += "\n" + previews
+			}
+			block = selectedMessageStyle.Render(block)
+			selectedStart, selectedHeight = lines, lipgloss.Height(block)
+		}
+		lines += lipgloss.Height(block)
+		blocks = append(blocks, block)
+	}
+	var prev *pb.Message
+	if len(msgs) > 0 {
+		prev = msgs[len(msgs)-1]
+	}
+	for _, p := range m.pending {
+		r := m.formatMessage(p.msg, m.cfg.GroupMessages && continuesGroup(prev, p.msg), formatPendingReceipt(p), data)
+		if r.err != nil {
+			templateErr = r.err
+		}
+		placeMessageLinks(p.msg, r, lines, 0)
+		lines += lipgloss.Height(r.block)
+		blocks = append(blocks, r.block)
+		prev = p.msg
+	}
+	m.viewport.SetContent(strings.Join(blocks, "\n"))
+
+	// The template fails again on every render, so it is only reported once
+	if templateErr != nil && !m.templateFailed {
+		m.templateFailed = true
+		m.msg = errorTextStyle.Render("Message template failed: " + templateErr.Error())
 	}
 
-	return fmtMsg
+	if m.focusedPanel != MESSSAGE_VIEW_PANEL {
+		return
+	}
+	if selectedStart < m.viewport.YOffset {
+		m.viewport.SetYOffset(selectedStart)
+	} else if end := selectedStart + selectedHeight; end > m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(end - m.viewport.Height)
+	}
 }
 
+// Moves the selection of the focused list, or of the chat view, by one
+func (m *chatModel) moveSelection(up bool) {
+	switch m.focusedPanel {
+	case MESSSAGE_VIEW_PANEL:
+		if up && m.selectedMsg > 0 {
+			m.selectedMsg--
+		} else if !up && m.selectedMsg < len(m.activeMessages())-1 {
+			m.selectedMsg++
+		}
+		m.showReactors = false
+		m.renderMessages()
+	case CHATS_PANEL:
+		if up {
+			m.chatList.CursorUp()
+		} else {
+			m.chatList.CursorDown()
+		}
+	case ACTIVE_REQUEST_PANEL:
+		if up {
+			m.requestsList.CursorUp()
+		} else {
+			m.requestsList.CursorDown()
+		}
+	case DETAILS_PANEL:
+		m.moveDetailsCursor(up)
+	}
+}
+
+// Opens the chat with id and starts streaming its messages
+func (m *chatModel) openChat(id string) tea.Cmd {
+	i := m.chatIndex(id)
+	if i == -1 {
+		return nil
+	}
+	if m.chatWriter != nil {
+		m.chatWriter.close()
+	}
+
+	m.msgChan = make(chan *pb.MessageStream)
+
+	chat := m.chatList.Items()[i].(chatItem)
+	chat.unread, chat.mentions = 0, 0
+	m.chatList.SetItem(i, chat)
+	m.selectChat(id)
+	if chat.id != m.activeChat {
+		m.pending = nil
+		m.readSent = ""
+	}
+	m.activeChat = chat.id
+	m.seen[chat.id] = len(chat.messages)
+
+	m.selectedMsg = len(chat.messages) - 1
+	m.commandHelp = false
+	m.detailsCursor = 0
+	m.typing = map[string]time.Time{}
+	m.typingSent = time.Time{}
+	m.confirmDelete = ""
+	m.focusedPanel = MESSAGE_PANEL
+	if m.normalMode() {
+		// Messages are read with the normal mode keys
+		m.focusedPanel = MESSSAGE_VIEW_PANEL
+	}
+	m.renderMessages()
+	m.viewport.GotoBottom()
+
+	err := m.initializeStream(chat.id)
+
+	if err != nil {
+		return tea.Quit
+	}
+
+	return tea.Batch(m.recv(), m.wait(), m.loadPreviews(chat.messages))
+}
+
+// The rows of the rendered view the sixel images and hyperlinks are drawn
+// over. bubbletea only repaints the rows that changed, so the overlays are
+// drawn again only when one of their rows did.
+type overlayState struct {
+	rows  string // the contents of the rows under the overlays
+	dirty bool   // the overlays were painted over since they were drawn
+}
+
+// Records a frame passed to bubbletea and the screen rows under the overlays
+func (o *overlayState) rendered(frame string, rows []int) {
+	lines := strings.Split(frame, "\n")
+	var b strings.Builder
+	for _, r := range rows {
+		fmt.Fprintf(&b, "%d\n", r)
+		if r < len(lines) {
+			b.WriteString(lines[r])
+		}
+		b.WriteByte('\n')
+	}
+	if b.String() != o.rows {
+		o.rows = b.String()
+		o.dirty = true
+	}
+}
+
+// Records the frame bubbletea renders after handling msg. The view is only
+// rendered here when there are overlays to follow. A resize repaints every
+// row.
+func (m chatModel) trackOverlays(msg tea.Msg) {
+	_, rows := m.overlay()
+	frame := ""
+	if len(rows) > 0 {
+		frame = m.View()
+	}
+	m.overlays.rendered(frame, rows)
+	if _, ok := msg.(tea.WindowSizeMsg); ok && len(rows) > 0 {
+		m.overlays.dirty = true
+	}
+}
+
+// Returns the sixel images and hyperlinks in view and the screen rows they
+// cover
+func (m chatModel) overlay() (string, []int) {
+	if m.linkPicker || m.commandHelp || m.palette {
+		return "", nil
+	}
+	// The viewport sits inside the borders of the chat view
+	p := m.panes()
+	vp := m.shownViewport()
+	sixels, sixelRows := m.previews.sixelOverlay(m.sixelPlacements, vp.YOffset, vp.Height, p.chatX, p.chatY)
+	links, linkRows := linkOverlay(m.links, vp.YOffset, vp.Height, p.chatX, p.chatY)
+	return sixels + links, append(sixelRows, linkRows...)
+}
+
+// Schedules drawing the sixel images and hyperlinks in view after the next
+// render. Both are written straight to the terminal, over the rendered view.
+func (m chatModel) drawOverlays() tea.Cmd {
+	if len(m.sixelPlacements) == 0 && len(m.links) == 0 {
+		return nil
+	}
+	return tea.Tick(overlayDrawDelay, func(time.Time) tea.Msg {
+		return statusMsg{sType: STATUS_OVERLAYS_DRAW}
+	})
+}
+
+// Queues msgStream to be sent on the stream of the open chat, after what was
+// queued before it
 func (c chatModel) send(msgStream *pb.MessageStream) tea.Cmd {
+	sent := c.chatWriter.send(msgStream)
 	return func() tea.Msg {
-		err := c.chatStream.Send(msgStream)
-
-		if err != nil {
-			return errMsg{err}
+		select {
+		case err := <-sent:
+			if err != nil {
+				return errMsg{err}
+			}
+			return statusMsg{sType: STATUS_MESSAGE_SEND}
+		case <-c.session.Done():
+			return nil
 		}
-		return statusMsg{sType: STATUS_MESSAGE_SEND}
 	}
 }
 
@@ -721,7 +1478,11 @@ func (c *chatModel) recv() tea.Cmd {
 			if err != nil {
 				return errMsg{err}
 			}
-			c.msgChan <- msg
+			select {
+			case c.msgChan <- msg:
+			case <-c.session.Done():
+				return nil
+			}
 		}
 
 	}
@@ -729,13 +1490,30 @@ func (c *chatModel) recv() tea.Cmd {
 
 func (c chatModel) wait() tea.Cmd {
 	return func() tea.Msg {
-		return statusMsg{sRes: <-c.msgChan, sType: STATUS_MESSAGE_RECV}
+		select {
+		case msg := <-c.msgChan:
+			return statusMsg{sRes: msg, sType: STATUS_MESSAGE_RECV}
+		case <-c.session.Done():
+			return nil
+		}
+	}
+}
+
+// Opens the stream of the active chat again, keeping what is shown of it
+func (m *chatModel) reopenChat() (tea.Cmd, error) {
+	if m.chatWriter != nil {
+		m.chatWriter.close()
 	}
+	if err := m.initializeStream(m.activeChat); err != nil {
+		return nil, err
+	}
+	m.msgChan = make(chan *pb.MessageStream)
+	return tea.Batch(m.recv(), m.wait()), nil
 }
 
 func (c *chatModel) initializeStream(chatID string) error {
 	meta := metadata.Pairs("authorization", fmt.Sprintf("Bearer %s", c.sessionToken), "stream_chat_id", chatID, "stream_username", c.user.Username)
-	ctx := metadata.NewOutgoingContext(context.Background(), meta)
+	ctx := metadata.NewOutgoingContext(c.session, meta)
 
 	stream, err := c.client.ChatStream(ctx)
 
@@ -743,16 +1521,22 @@ func (c *chatModel) initializeStream(chatID string) error {
 		return err
 	}
 
-	c.chatStream = stream
+	c.setChatStream(stream)
 
 	return nil
 }
 
+// Makes stream the stream of the open chat
+func (c *chatModel) setChatStream(stream pb.ChatService_ChatStreamClient) {
+	c.chatStream = stream
+	c.chatWriter = newStreamWriter(c.session, stream)
+}
+
 func (c chatModel) sendGroupChatJoinRequest(groupName, groupPasskey string) tea.Cmd {
 	return func() tea.Msg {
 		meta := metadata.Pairs("authorization", fmt.Sprintf("Bearer %s", c.sessionToken))
 
-		ctx := metadata.NewOutgoingContext(context.Background(), meta)
+		ctx := metadata.NewOutgoingContext(c.session, meta)
 
 		res, err := c.client.JoinGroupChat(ctx, &pb.GroupChatRequest{GroupName: groupName, GroupPasskey: groupPasskey})
 		if err != nil {
@@ -767,7 +1551,7 @@ func (c chatModel) sendGroupChatCreateRequest(groupName, groupPasskey string) te
 	return func() tea.Msg {
 		meta := metadata.Pairs("authorization", fmt.Sprintf("Bearer %s", c.sessionToken))
 
-		ctx := metadata.NewOutgoingContext(context.Background(), meta)
+		ctx := metadata.NewOutgoingContext(c.session, meta)
 
 		res, err := c.client.CreateGroupChat(ctx, &pb.GroupChatRequest{GroupName: groupName, GroupPasskey: groupPasskey})
 		if err != nil {
@@ -782,7 +1566,7 @@ func (c chatModel) getChats() tea.Cmd {
 	return func() tea.Msg {
 		meta := metadata.Pairs("authorization", fmt.Sprintf("Bearer %s", c.sessionToken))
 
-		ctx := metadata.NewOutgoingContext(context.Background(), meta)
+		ctx := metadata.NewOutgoingContext(c.session, meta)
 
 		res, err := c.client.GetChats(ctx, &emptypb.Empty{})
 		if err != nil {
@@ -791,12 +1575,34 @@ func (c chatModel) getChats() tea.Cmd {
 
 		var chatItems []chatItem
 		for _, v := range res.Chats {
+			var partner string
+			for _, u := range v.Members {
+				if u.Username != c.user.Username {
+					partner = u.Username
+				}
+			}
+			var createdAt time.Time
+			if v.CreatedAt != nil {
+				createdAt = v.CreatedAt.AsTime()
+			}
+			reads := map[string]string{}
+			for _, r := range v.ReadMarkers {
+				reads[r.User.GetUsername()] = r.MessageId
+			}
+			roles := map[string]pb.GroupRole{}
+			for _, r := range v.Roles {
+				roles[r.User.GetUsername()] = r.Role
+			}
 			chatItems = append(chatItems, chatItem{
-				id:       v.Id,
-				name:     *v.Name,
-				messages: v.Messages,
-				chatType: v.Type,
-				members:  v.Members,
+				id:        v.Id,
+				name:      *v.Name,
+				partner:   partner,
+				messages:  v.Messages,
+				chatType:  v.Type,
+				members:   v.Members,
+				createdAt: createdAt,
+				reads:     reads,
+				roles:     roles,
 			})
 		}
 		return statusMsg{sType: STATUS_CHATS_LOAD, sRes: chatItems}
@@ -807,7 +1613,7 @@ func (c chatModel) sendDirectChatJoinRequest(receiver string) tea.Cmd {
 	return func() tea.Msg {
 		meta := metadata.Pairs("authorization", fmt.Sprintf("Bearer %s", c.sessionToken))
 
-		ctx := metadata.NewOutgoingContext(context.Background(), meta)
+		ctx := metadata.NewOutgoingContext(c.session, meta)
 
 		res, err := c.client.JoinDirectChat(ctx, &pb.JoinDirectChatRequest{SentAt: timestamppb.Now(), Receiver: &pb.User{Username: receiver}})
 		if err != nil {
@@ -822,7 +1628,7 @@ func (c chatModel) getRequests() tea.Cmd {
 	return func() tea.Msg {
 		meta := metadata.Pairs("authorization", fmt.Sprintf("Bearer %s", c.sessionToken))
 
-		ctx := metadata.NewOutgoingContext(context.Background(), meta)
+		ctx := metadata.NewOutgoingContext(c.session, meta)
 
 		res, err := c.client.GetDirectChatRequests(ctx, &emptypb.Empty{})
 		if err != nil {
@@ -844,7 +1650,7 @@ func (c chatModel) sendRequestAction(chatRequestId string, action pb.DirectChatA
 	return func() tea.Msg {
 		meta := metadata.Pairs("authorization", fmt.Sprintf("Bearer %s", c.sessionToken))
 
-		ctx := metadata.NewOutgoingContext(context.Background(), meta)
+		ctx := metadata.NewOutgoingContext(c.session, meta)
 
 		res, err := c.client.DirectChatRequestAction(ctx, &pb.DirectChatAction{Action: action, Id: chatRequestId})
 		if err != nil {
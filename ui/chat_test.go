@@ -0,0 +1,260 @@
+package ui
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// A chat stream that records what is sent on it and receives nothing
+type fakeChatStream struct {
+	grpc.ClientStream
+	mu         sync.Mutex
+	sent       []*pb.MessageStream
+	err        error // returned by Send
+	sending    atomic.Int32
+	concurrent atomic.Bool // Send was called while another call was running
+}
+
+func (s *fakeChatStream) Send(msg *pb.MessageStream) error {
+	if s.sending.Add(1) > 1 {
+		s.concurrent.Store(true)
+	}
+	defer s.sending.Add(-1)
+	time.Sleep(time.Millisecond)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func (s *fakeChatStream) Recv() (*pb.MessageStream, error) { return nil, io.EOF }
+
+func (s *fakeChatStream) CloseSend() error { return nil }
+
+// A client whose chat streams are stream, or fail with err
+type fakeChatClient struct {
+	pb.ChatServiceClient
+	stream *fakeChatStream
+	err    error
+}
+
+func (c *fakeChatClient) ChatStream(ctx context.Context, opts ...grpc.CallOption) (pb.ChatService_ChatStreamClient, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.stream, nil
+}
+
+// Returns a chat model logged in as "me" with chats already loaded, so
+// Update handles messages straight away
+func newTestChatModel(chats ...chatItem) chatModel {
+	m := NewChatModel(nil, 120, 40, &pb.UserAuthenticatedResponse{User: &pb.User{Username: "me"}}, DefaultConfig())
+	m.chatsLoaded, m.requestsLoaded = true, true
+
+	items := make([]list.Item, len(chats))
+	for i, c := range chats {
+		items[i] = c
+	}
+	m.chatList.SetItems(items)
+	return m
+}
+
+func testMessage(id, sender string, at time.Time) *pb.Message {
+	return &pb.Message{
+		Id:      id,
+		Sender:  &pb.User{Username: sender},
+		Content: id,
+		SentAt:  timestamppb.New(at),
+		Type:    pb.Message_MESSAGE_TYPE_REGULAR,
+	}
+}
+
+func TestReceivedMessageGoesToOpenChat(t *testing.T) {
+	m := newTestChatModel(
+		chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP},
+		chatItem{id: "b", name: "b", chatType: pb.ChatType_CHAT_TYPE_GROUP},
+	)
+	m.activeChat = "a"
+	// The cursor of the chat list is moved off the open chat
+	m.chatList.Select(1)
+
+	msg := testMessage("1", "alice", time.Now())
+	model, _ := m.Update(statusMsg{sType: STATUS_MESSAGE_RECV, sRes: &pb.MessageStream{ChatId: "a", Message: msg}})
+	m = model.(chatModel)
+
+	for _, v := range m.chatList.Items() {
+		chat := v.(chatItem)
+		want := 0
+		if chat.id == "a" {
+			want = 1
+		}
+		if len(chat.messages) != want {
+			t.Errorf("chat %s has %d messages, want %d", chat.id, len(chat.messages), want)
+		}
+	}
+}
+
+func TestReceivedFrameWithoutMessage(t *testing.T) {
+	m := newTestChatModel(chatItem{
+		id:       "a",
+		name:     "a",
+		chatType: pb.ChatType_CHAT_TYPE_GROUP,
+		messages: []*pb.Message{testMessage("1", "alice", time.Now())},
+	})
+	m.activeChat = "a"
+
+	// A frame of a kind this client does not know carries none of the fields
+	model, _ := m.Update(statusMsg{sType: STATUS_MESSAGE_RECV, sRes: &pb.MessageStream{ChatId: "a"}})
+	m = model.(chatModel)
+
+	if _, chat, _ := m.activeChatItem(); len(chat.messages) != 1 {
+		t.Errorf("chat has %d messages after an empty frame, want 1", len(chat.messages))
+	}
+}
+
+func TestSendAfterEditClearsInput(t *testing.T) {
+	m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.cfg.SendAfterEdit = true
+	m.activeChat = "a"
+	m.setChatStream(&fakeChatStream{})
+	m.input.SetValue("old draft")
+
+	model, cmd := m.Update(statusMsg{sType: STATUS_DRAFT_EDIT, sRes: "//edited"})
+	m = model.(chatModel)
+	if m.input.Value() != "" {
+		t.Errorf("input holds %q after sending the edited draft", m.input.Value())
+	}
+	if cmd == nil || len(m.pending) != 1 || m.pending[0].msg.Content != "/edited" {
+		t.Fatalf("the edited draft was not sent unescaped: %v", m.pending)
+	}
+}
+
+func TestSendAfterEditRunsCommands(t *testing.T) {
+	m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.cfg.SendAfterEdit = true
+	m.activeChat = "a"
+	m.setChatStream(&fakeChatStream{})
+
+	model, _ := m.Update(statusMsg{sType: STATUS_DRAFT_EDIT, sRes: "/help"})
+	m = model.(chatModel)
+	if !m.commandHelp || len(m.pending) != 0 {
+		t.Error("a command written in the editor was sent as a message")
+	}
+}
+
+func TestTypedPathIsSentAsText(t *testing.T) {
+	// A file name typed as a message, relative to where the client runs
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	path := "go.mod"
+	if err := os.WriteFile(path, []byte("module secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.activeChat = "a"
+	m.setChatStream(&fakeChatStream{})
+	m.focusedPanel = MESSAGE_PANEL
+	m.input.SetValue(path)
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(chatModel)
+	if len(m.pending) != 1 || m.pending[0].msg.Content != path {
+		t.Fatalf("a typed path was not sent as text, msg %q", m.msg)
+	}
+}
+
+func TestPastedPathIsUploadedOnEnter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "photo.png")
+	if err := os.WriteFile(path, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.activeChat = "a"
+	m.setChatStream(&fakeChatStream{})
+	m.focusedPanel = MESSAGE_PANEL
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(path), Paste: true})
+	m = model.(chatModel)
+	if m.input.Value() != path || !strings.Contains(m.msg, "upload") {
+		t.Fatalf("pasting a path did not offer to upload it, input %q, msg %q", m.input.Value(), m.msg)
+	}
+
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(chatModel)
+	if cmd == nil || len(m.pending) != 0 || !strings.Contains(m.msg, "Uploading photo.png") {
+		t.Fatalf("the pasted file was not uploaded, msg %q", m.msg)
+	}
+}
+
+func TestEditedPastedPathIsSentAsText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "photo.png")
+	if err := os.WriteFile(path, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.activeChat = "a"
+	m.setChatStream(&fakeChatStream{})
+	m.focusedPanel = MESSAGE_PANEL
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("see " + path), Paste: true})
+	m = model.(chatModel)
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(chatModel)
+	if len(m.pending) != 1 {
+		t.Fatalf("a message containing a path was not sent as text, msg %q", m.msg)
+	}
+}
+
+func TestOpenChatKeepsPendingOfSameChat(t *testing.T) {
+	tests := []struct {
+		name        string
+		open        string
+		keepPending bool
+	}{
+		{"same chat", "a", true},
+		{"other chat", "b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestChatModel(
+				chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP},
+				chatItem{id: "b", name: "b", chatType: pb.ChatType_CHAT_TYPE_GROUP},
+			)
+			m.client = &fakeChatClient{stream: &fakeChatStream{}}
+			m.activeChat = "a"
+			m.pending = []pendingMessage{{msg: testMessage("1", "me", time.Now()), failed: true}}
+			m.readSent = "0"
+
+			m.openChat(tt.open)
+			if kept := len(m.pending) == 1; kept != tt.keepPending {
+				t.Errorf("pending kept = %v, want %v", kept, tt.keepPending)
+			}
+			if kept := m.readSent == "0"; kept != tt.keepPending {
+				t.Errorf("read marker kept = %v, want %v", kept, tt.keepPending)
+			}
+		})
+	}
+}
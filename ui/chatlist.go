@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"sort"
+)
+
+// Orders chats by their latest message, most recent first
+func sortByActivity(chats []chatItem) {
+	sort.SliceStable(chats, func(i, j int) bool {
+		return chats[i].lastActivity().After(chats[j].lastActivity())
+	})
+}
+
+// Returns the index of the chat with id among all chats, or -1
+func (m chatModel) chatIndex(id string) int {
+	for i, v := range m.chatList.Items() {
+		if v.(chatItem).id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns the chat with an open stream and its index among all chats
+func (m chatModel) activeChatItem() (int, chatItem, bool) {
+	i := m.chatIndex(m.activeChat)
+	if i == -1 {
+		return i, chatItem{}, false
+	}
+	return i, m.chatList.Items()[i].(chatItem), true
+}
+
+// Moves the cursor of the chat list to the chat with id, if it is shown
+func (m *chatModel) selectChat(id string) {
+	for i, v := range m.chatList.VisibleItems() {
+		if v.(chatItem).id == id {
+			m.chatList.Select(i)
+			return
+		}
+	}
+}
+
+func (m chatModel) selectedChatID() string {
+	chat, ok := m.chatList.SelectedItem().(chatItem)
+	if !ok {
+		return ""
+	}
+	return chat.id
+}
@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func chatListTestModel() chatModel {
+	m := newTestChatModel(
+		chatItem{id: "g", name: "gophers", chatType: pb.ChatType_CHAT_TYPE_GROUP},
+		chatItem{id: "b", chatType: pb.ChatType_CHAT_TYPE_DIRECT, partner: "bob", members: []*pb.User{{Username: "me"}, {Username: "bob"}}},
+		chatItem{id: "c", chatType: pb.ChatType_CHAT_TYPE_DIRECT, partner: "carol", members: []*pb.User{{Username: "me"}, {Username: "carol"}}},
+	)
+	m.focusedPanel = CHATS_PANEL
+	return m
+}
+
+// Runs cmd and the commands it batches until one returns the matches of the
+// chat list filter. Commands still waiting, such as cursor blinks, are left.
+func filterMatches(t *testing.T, cmd tea.Cmd) list.FilterMatchesMsg {
+	t.Helper()
+	msgs := make(chan tea.Msg, 16)
+	var run func(cmd tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		go func() {
+			msg := cmd()
+			if batch, ok := msg.(tea.BatchMsg); ok {
+				for _, c := range batch {
+					run(c)
+				}
+				return
+			}
+			msgs <- msg
+		}()
+	}
+	run(cmd)
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case msg := <-msgs:
+			if matches, ok := msg.(list.FilterMatchesMsg); ok {
+				return matches
+			}
+		case <-timeout:
+			t.Fatal("the chat list was not filtered")
+		}
+	}
+}
+
+// Types text into the filter of the chat list and applies the matches
+func filterChats(t *testing.T, m chatModel, text string) chatModel {
+	t.Helper()
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	model, _ = model.Update(filterMatches(t, cmd))
+	return model.(chatModel)
+}
+
+func visibleChatIDs(m chatModel) []string {
+	var ids []string
+	for _, v := range m.chatList.VisibleItems() {
+		ids = append(ids, v.(chatItem).id)
+	}
+	return ids
+}
+
+func TestFilterChatsByPartner(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"bob", []string{"b"}},
+		{"carol", []string{"c"}},
+		{"gophers", []string{"g"}},
+		// The user is a member of every direct chat
+		{"me", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			got := visibleChatIDs(filterChats(t, chatListTestModel(), tt.filter))
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("filter %q shows chats %q, want %q", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+// Returns the chats of the test model as loaded again, with a new message
+// moving carol to the top
+func reloadedChats() statusMsg {
+	m := chatListTestModel()
+	var chats []chatItem
+	for _, v := range m.chatList.Items() {
+		chat := v.(chatItem)
+		if chat.id == "c" {
+			chat.messages = []*pb.Message{testMessage("1", "carol", time.Now())}
+		}
+		chats = append(chats, chat)
+	}
+	return statusMsg{sType: STATUS_CHATS_LOAD, sRes: chats}
+}
+
+func TestChatSelectionKeptOnRefresh(t *testing.T) {
+	m := chatListTestModel()
+	m.selectChat("b")
+
+	model, _ := m.Update(reloadedChats())
+	m = model.(chatModel)
+	if got := m.chatList.Items()[0].(chatItem).id; got != "c" {
+		t.Fatalf("chat %q is first after a new message to c", got)
+	}
+	if got := m.selectedChatID(); got != "b" {
+		t.Errorf("chat %q is selected after the refresh, want b", got)
+	}
+}
+
+func TestFilteredChatSelectionKeptOnRefresh(t *testing.T) {
+	m := filterChats(t, chatListTestModel(), "o")
+	m.selectChat("c")
+
+	model, cmd := m.Update(reloadedChats())
+	model, _ = model.Update(filterMatches(t, cmd))
+	m = model.(chatModel)
+	if got := m.selectedChatID(); got != "c" {
+		t.Errorf("chat %q is selected after the refresh, want c", got)
+	}
+}
@@ -0,0 +1,338 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+type slashCommand struct {
+	name    string
+	args    string // usage of the arguments i.e. <name> <passkey>
+	desc    string
+	minArgs int
+	maxArgs int // -1 if the last argument takes the rest of the input
+}
+
+// Commands available in the message input, in the order they are listed
+var slashCommands = []slashCommand{
+	{name: "/join", args: "<name> <passkey>", desc: "join a group", minArgs: 2, maxArgs: 2},
+	{name: "/create", args: "<name> <passkey>", desc: "create a group", minArgs: 2, maxArgs: 2},
+	{name: "/msg", args: "<username>", desc: "open a direct chat, sending a request if needed", minArgs: 1, maxArgs: 1},
+	{name: "/accept", args: "[username]", desc: "accept a chat request", minArgs: 0, maxArgs: 1},
+	{name: "/reject", args: "[username]", desc: "reject a chat request", minArgs: 0, maxArgs: 1},
+	{name: "/me", args: "<action>", desc: "send an action i.e. /me waves", minArgs: 1, maxArgs: -1},
+	{name: "/upload", args: "<path>", desc: "send a file", minArgs: 1, maxArgs: -1},
+	{name: "/kick", args: "<username>", desc: "remove a member from the group", minArgs: 1, maxArgs: 1},
+	{name: "/ban", args: "<username>", desc: "remove a member and stop them joining again", minArgs: 1, maxArgs: 1},
+	{name: "/promote", args: "<username>", desc: "make a member an admin", minArgs: 1, maxArgs: 1},
+	{name: "/demote", args: "<username>", desc: "make an admin a member", minArgs: 1, maxArgs: 1},
+	{name: "/rename", args: "<name>", desc: "rename the group", minArgs: 1, maxArgs: -1},
+	{name: "/passkey", args: "<passkey>", desc: "change the passkey of the group", minArgs: 1, maxArgs: 1},
+	{name: "/retry", desc: "send the messages that failed to send again", minArgs: 0, maxArgs: 0},
+	{name: "/discard", desc: "drop the messages that failed to send", minArgs: 0, maxArgs: 0},
+	{name: "/help", desc: "list the commands", minArgs: 0, maxArgs: 0},
+}
+
+func findCommand(name string) (slashCommand, bool) {
+	for _, c := range slashCommands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return slashCommand{}, false
+}
+
+func (c slashCommand) usage() string {
+	return strings.TrimSpace(c.name + " " + c.args)
+}
+
+// Checks if input is a command. Messages starting with a slash are sent by
+// doubling it i.e. //shrug
+func isCommand(input string) bool {
+	return strings.HasPrefix(input, "/") && !strings.HasPrefix(input, "//")
+}
+
+// Splits a command into its name and arguments. The arguments after maxArgs
+// are kept together as the last one.
+func parseCommand(input string) (slashCommand, []string, error) {
+	fields := strings.Fields(input)
+	cmd, ok := findCommand(fields[0])
+	if !ok {
+		return cmd, nil, fmt.Errorf("Unknown command %s, see /help", fields[0])
+	}
+
+	args := fields[1:]
+	if cmd.maxArgs == -1 && len(args) > cmd.minArgs {
+		rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(input), fields[0]))
+		for i := 0; i < cmd.minArgs-1; i++ {
+			rest = strings.TrimSpace(strings.TrimPrefix(rest, args[i]))
+		}
+		args = append(args[:max(0, cmd.minArgs-1)], rest)
+	}
+	if len(args) < cmd.minArgs || (cmd.maxArgs != -1 && len(args) > cmd.maxArgs) {
+		return cmd, nil, fmt.Errorf("Usage: %s", cmd.usage())
+	}
+	return cmd, args, nil
+}
+
+// Runs the command typed in the message input. Errors are shown above the
+// input so the command can be corrected.
+func (m chatModel) runCommand(input string) (tea.Model, tea.Cmd) {
+	cmd, args, err := parseCommand(input)
+	if err != nil {
+		m.commandErr = err.Error()
+		return m, nil
+	}
+
+	var teaCmd tea.Cmd
+	switch cmd.name {
+	case "/join", "/create":
+		m.joinGroupLoading = true
+		if cmd.name == "/join" {
+			teaCmd = tea.Batch(m.progressIndicator.Tick, m.sendGroupChatJoinRequest(args[0], args[1]))
+		} else {
+			teaCmd = tea.Batch(m.progressIndicator.Tick, m.sendGroupChatCreateRequest(args[0], args[1]))
+		}
+	case "/msg":
+		if args[0] == m.user.Username {
+			m.commandErr = "You cannot message yourself"
+			return m, nil
+		}
+		teaCmd = m.directChat(args[0])
+	case "/accept", "/reject":
+		i, ok := m.findRequest(args)
+		if !ok {
+			if len(args) == 0 {
+				m.commandErr = "No chat requests"
+			} else {
+				m.commandErr = "No chat request from " + args[0]
+			}
+			return m, nil
+		}
+		action := pb.DirectChatAction_ACTION_ACCEPT
+		if cmd.name == "/reject" {
+			action = pb.DirectChatAction_ACTION_REJECT
+		}
+		teaCmd = m.answerRequest(i, action)
+	case "/me":
+		if m.chatStream == nil {
+			m.commandErr = "Open a chat first"
+			return m, nil
+		}
+		teaCmd = m.sendMessage(fmt.Sprintf("_%s %s_", m.user.Username, args[0]))
+	case "/upload":
+		if m.chatStream == nil {
+			m.commandErr = "Open a chat first"
+			return m, nil
+		}
+		path, ok := parseAttachmentPath(args[0])
+		if !ok {
+			m.commandErr = "No such file: " + args[0]
+			return m, nil
+		}
+		m.msg = notificationTextStyle.Render("Uploading " + filepath.Base(path))
+		teaCmd = m.uploadAttachment(m.activeChat, path)
+	case "/kick", "/ban", "/promote", "/demote", "/rename", "/passkey":
+		teaCmd, err = m.groupCommand(cmd.name, args)
+		if err != nil {
+			m.commandErr = err.Error()
+			return m, nil
+		}
+	case "/retry":
+		if m.chatStream == nil {
+			m.commandErr = "Open a chat first"
+			return m, nil
+		}
+		if !m.hasFailed() {
+			m.commandErr = "No messages failed to send"
+			return m, nil
+		}
+		teaCmd = m.retryPending()
+	case "/discard":
+		if m.discardFailed() == 0 {
+			m.commandErr = "No messages failed to send"
+			return m, nil
+		}
+		m.msg = notificationTextStyle.Render("Dropped the messages that failed to send")
+	case "/help":
+		m.commandHelp = true
+	}
+
+	if cmd.name != "/help" {
+		m.commandHelp = false
+	}
+	m.input.Reset()
+	m.fitInput()
+
+	return m, teaCmd
+}
+
+// Opens the direct chat with username, sending them a chat request if there
+// is none yet
+func (m *chatModel) directChat(username string) tea.Cmd {
+	if id, ok := m.findDirectChat(username); ok {
+		return m.openChat(id)
+	}
+	m.sendRequestLoading = true
+	return tea.Batch(m.progressIndicator.Tick, m.sendDirectChatJoinRequest(username))
+}
+
+// Returns the id of the direct chat with username
+func (m chatModel) findDirectChat(username string) (string, bool) {
+	for _, v := range m.chatList.Items() {
+		chat := v.(chatItem)
+		if chat.chatType == pb.ChatType_CHAT_TYPE_DIRECT && chat.partner == username {
+			return chat.id, true
+		}
+	}
+	return "", false
+}
+
+// Accepts or rejects the chat request at index i of the requests list
+func (m *chatModel) answerRequest(i int, action pb.DirectChatAction_Action) tea.Cmd {
+	req := m.requestsList.Items()[i].(requestItem)
+	m.requestsList.RemoveItem(i)
+	return m.sendRequestAction(req.id, action)
+}
+
+// Returns the index of the chat request from the username in args, or of the
+// selected request if no username is given
+func (m chatModel) findRequest(args []string) (int, bool) {
+	items := m.requestsList.Items()
+	if len(args) == 0 {
+		return m.requestsList.Index(), len(items) > 0
+	}
+	for i, v := range items {
+		if v.(requestItem).name == args[0] {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Suggests command names, or the arguments of the command being typed
+func (m chatModel) commandSuggestions(word string) []suggestion {
+	value := m.input.Value()
+	if !strings.ContainsAny(value, " \t") {
+		var items []suggestion
+		for _, c := range slashCommands {
+			if strings.HasPrefix(c.name, word) {
+				items = append(items, suggestion{value: c.name + " ", desc: strings.TrimSpace(c.args + " " + c.desc)})
+			}
+		}
+		return items
+	}
+
+	fields := strings.Fields(value)
+	arg := len(fields) - 1
+	if word == "" {
+		arg++
+	}
+
+	var options []string
+	switch {
+	case fields[0] == "/msg" && arg == 1:
+		options = m.knownUsers()
+	case (fields[0] == "/accept" || fields[0] == "/reject") && arg == 1:
+		for _, v := range m.requestsList.Items() {
+			options = append(options, v.(requestItem).name)
+		}
+	case fields[0] == "/upload" && arg == 1:
+		return pathSuggestions(word)
+	default:
+		return nil
+	}
+
+	var items []suggestion
+	if word == "" {
+		for _, o := range options {
+			items = append(items, suggestion{value: o + " "})
+		}
+		return items
+	}
+	for _, match := range fuzzy.Find(word, options) {
+		items = append(items, suggestion{value: match.Str + " "})
+	}
+	return items
+}
+
+// Returns the usernames of everyone in the user's chats
+func (m chatModel) knownUsers() []string {
+	seen := map[string]bool{m.user.Username: true}
+	var users []string
+	for _, v := range m.chatList.Items() {
+		for _, u := range v.(chatItem).members {
+			if !seen[u.Username] {
+				seen[u.Username] = true
+				users = append(users, u.Username)
+			}
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// Suggests the files and directories starting with the path being typed
+func pathSuggestions(word string) []suggestion {
+	dir, prefix := filepath.Split(word)
+	expanded := dir
+	if strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			expanded = filepath.Join(home, dir[2:]) + "/"
+		}
+	}
+	if expanded == "" {
+		expanded = "."
+	}
+
+	entries, err := os.ReadDir(expanded)
+	if err != nil {
+		return nil
+	}
+	var items []suggestion
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		if e.IsDir() {
+			items = append(items, suggestion{value: dir + name + "/"})
+		} else {
+			items = append(items, suggestion{value: dir + name})
+		}
+	}
+	return items
+}
+
+// Renders the list of commands to fill the viewport
+func (m chatModel) formatCommandHelp(width, height int) string {
+	lines := []string{senderTextStyle.Render("Commands") + " " + suggestionDescStyle.Render(m.keys.Cancel.Help().Key+" close")}
+
+	usageWidth := 0
+	for _, c := range slashCommands {
+		usageWidth = max(usageWidth, lipgloss.Width(c.usage()))
+	}
+	for _, c := range slashCommands {
+		usage := defaultStyle.Copy().Width(usageWidth + 2).Render(c.usage())
+		lines = append(lines, usage+suggestionDescStyle.Render(c.desc))
+	}
+	lines = append(lines, "", suggestionDescStyle.Render("Start a message with // to send it with a leading /"))
+
+	return defaultStyle.Copy().Width(width).Height(height).MaxHeight(height).Render(strings.Join(lines, "\n"))
+}
+
+// Removes the escaping slash of a message starting with //
+func unescapeCommand(input string) string {
+	if strings.HasPrefix(input, "//") {
+		return input[1:]
+	}
+	return input
+}
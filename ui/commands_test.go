@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Ayobami0/cli-chat/pb"
+)
+
+func TestFindDirectChat(t *testing.T) {
+	m := newTestChatModel(
+		chatItem{id: "g", name: "alice", chatType: pb.ChatType_CHAT_TYPE_GROUP},
+		chatItem{id: "d", chatType: pb.ChatType_CHAT_TYPE_DIRECT, partner: "alice"},
+	)
+	tests := []struct {
+		username string
+		id       string
+		ok       bool
+	}{
+		{"alice", "d", true},
+		{"bob", "", false},
+	}
+	for _, tt := range tests {
+		id, ok := m.findDirectChat(tt.username)
+		if id != tt.id || ok != tt.ok {
+			t.Errorf("findDirectChat(%q) = %q, %v, want %q, %v", tt.username, id, ok, tt.id, tt.ok)
+		}
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		input string
+		name  string
+		args  []string
+		err   string
+	}{
+		{"/join room secret", "/join", []string{"room", "secret"}, ""},
+		{"/join room", "/join", nil, "Usage: /join <name> <passkey>"},
+		{"/join room secret extra", "/join", nil, "Usage: /join <name> <passkey>"},
+		{"/accept", "/accept", []string{}, ""},
+		{"/accept bob", "/accept", []string{"bob"}, ""},
+		{"/me waves at  everyone", "/me", []string{"waves at  everyone"}, ""},
+		{"/me", "/me", nil, "Usage: /me <action>"},
+		{"/upload my photo.png", "/upload", []string{"my photo.png"}, ""},
+		{"/rename  New  Name ", "/rename", []string{"New  Name"}, ""},
+		{"/retry now", "/retry", nil, "Usage: /retry"},
+		{"/nope", "", nil, "Unknown command /nope, see /help"},
+	}
+	for _, tt := range tests {
+		cmd, args, err := parseCommand(tt.input)
+		var got string
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.err || cmd.name != tt.name || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("parseCommand(%q) = %s %q, %q, want %s %q, %q", tt.input, cmd.name, args, got, tt.name, tt.args, tt.err)
+		}
+	}
+}
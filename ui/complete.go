@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Max number of suggestions shown above the message input
+const maxSuggestions = 5
+
+type suggestion struct {
+	value string // replaces the word being completed
+	desc  string
+}
+
+type suggestions struct {
+	items []suggestion
+	idx   int
+}
+
+func (s suggestions) visible() bool { return len(s.items) > 0 }
+
+func (s suggestions) selected() suggestion { return s.items[s.idx] }
+
+func (s *suggestions) next() {
+	if s.idx == len(s.items)-1 {
+		s.idx = 0
+	} else {
+		s.idx++
+	}
+}
+
+func (s *suggestions) prev() {
+	if s.idx == 0 {
+		s.idx = len(s.items) - 1
+	} else {
+		s.idx--
+	}
+}
+
+// Number of lines taken by the suggestions when rendered
+func (s suggestions) height() int {
+	if !s.visible() {
+		return 0
+	}
+	return min(len(s.items), maxSuggestions)
+}
+
+func (s suggestions) View() string {
+	if !s.visible() {
+		return ""
+	}
+
+	// Scroll so the selected suggestion is always shown
+	start := max(0, s.idx-maxSuggestions+1)
+	end := min(len(s.items), start+maxSuggestions)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		item := s.items[i]
+		line := item.value
+		if item.desc != "" {
+			line += " " + suggestionDescStyle.Render(item.desc)
+		}
+		if i == s.idx {
+			lines = append(lines, selectedSuggestionStyle.Render("> "+line))
+			continue
+		}
+		lines = append(lines, suggestionStyle.Render("  "+line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Returns the word directly before the cursor of the input
+func wordBeforeCursor(ta textarea.Model) string {
+	lines := strings.Split(ta.Value(), "\n")
+	if ta.Line() >= len(lines) {
+		return ""
+	}
+	line := []rune(lines[ta.Line()])
+	li := ta.LineInfo()
+	col := min(li.StartColumn+li.CharOffset, len(line))
+
+	start := col
+	for start > 0 && line[start-1] != ' ' && line[start-1] != '\t' {
+		start--
+	}
+	return string(line[start:col])
+}
+
+// Replaces the word directly before the cursor of the input with s
+func replaceWordBeforeCursor(ta *textarea.Model, s string) {
+	replaceBeforeCursor(ta, len([]rune(wordBeforeCursor(*ta))), s)
+}
+
+// Replaces the n characters directly before the cursor of the input with s
+func replaceBeforeCursor(ta *textarea.Model, n int, s string) {
+	for i := 0; i < n; i++ {
+		*ta, _ = ta.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	ta.InsertString(s)
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// User preferences loaded from the config file
+type Config struct {
+	// Max number of lines the message input grows to
+	InputMaxHeight int `json:"input_max_height"`
+	// Send messages written in the editor as soon as it exits instead of
+	// loading them back into the message input
+	SendAfterEdit bool `json:"send_after_edit"`
+	// Directory attachments are downloaded to. Defaults to ~/Downloads
+	DownloadDir string `json:"download_dir"`
+	// How image attachments are previewed. One of auto, kitty, sixel, blocks
+	// or off
+	ImagePreviews string `json:"image_previews"`
+	// How keys are handled. One of default or vim, which adds a normal mode
+	// for moving around and an insert mode for typing
+	InputMode string `json:"input_mode"`
+	// How message times are shown. One of absolute, relative or off
+	Timestamps string `json:"timestamps"`
+	// Either 24h or 12h
+	Clock string `json:"clock"`
+	// Show consecutive messages of a sender under a single name
+	GroupMessages bool `json:"group_messages"`
+	// Layout of messages. Either one of the built-in templates or a
+	// text/template
+	MessageTemplate string `json:"message_template"`
+	messageTemplate *template.Template
+	// Colours of the interface. Either auto, one of the built-in themes or a
+	// theme file
+	Theme string `json:"theme"`
+	theme Theme
+	// Time without input after which you are shown as away, such as 5m. 0
+	// never shows you as away
+	AwayAfter string `json:"away_after"`
+	awayAfter time.Duration
+	// Handle clicks and the mouse wheel. Text is selected with shift held
+	// while it is on
+	Mouse bool `json:"mouse"`
+	// Rules turning tokens such as ticket numbers into links
+	Linkifiers []LinkRule `json:"linkifiers"`
+	// Keys bound to each action, replacing its default keys. An empty list
+	// unbinds the action
+	Keys map[string][]string `json:"keys"`
+}
+
+// Links the text matching Pattern to URL. URL may refer to the groups of the
+// pattern i.e. $1 or ${name}.
+type LinkRule struct {
+	Pattern string `json:"pattern"`
+	URL     string `json:"url"`
+	re      *regexp.Regexp
+}
+
+func DefaultConfig() Config {
+	return Config{
+		InputMaxHeight: 5,
+		ImagePreviews:  "auto",
+		InputMode:      "default",
+		Timestamps:     "absolute",
+		Clock:          "24h",
+		GroupMessages:  true,
+		// The built-in templates are known to parse
+		MessageTemplate: "default",
+		messageTemplate: template.Must(parseMessageTemplate("default")),
+		Theme:           "auto",
+		AwayAfter:       "5m",
+		awayAfter:       5 * time.Minute,
+	}
+}
+
+// Returns the directory attachments are downloaded to
+func (c Config) downloadDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	if c.DownloadDir == "" {
+		return filepath.Join(home, "Downloads")
+	}
+	if strings.HasPrefix(c.DownloadDir, "~/") {
+		return filepath.Join(home, c.DownloadDir[2:])
+	}
+	return c.DownloadDir
+}
+
+// Returns the default location of the config file
+// i.e. ~/.config/cli-chat/config.json on linux
+func DefaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cli-chat", "config.json"), nil
+}
+
+// Reads the config file at path. Settings missing from the file, or a missing
+// file, fall back to the defaults.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	if cfg.InputMaxHeight < 1 {
+		return cfg, fmt.Errorf("invalid config %s: input_max_height must be at least 1", path)
+	}
+	switch cfg.ImagePreviews {
+	case "auto", "kitty", "sixel", "blocks", "off":
+	default:
+		return cfg, fmt.Errorf("invalid config %s: unknown image_previews %q", path, cfg.ImagePreviews)
+	}
+	switch cfg.InputMode {
+	case "default", "vim":
+	default:
+		return cfg, fmt.Errorf("invalid config %s: unknown input_mode %q", path, cfg.InputMode)
+	}
+	switch cfg.Timestamps {
+	case "absolute", "relative", "off":
+	default:
+		return cfg, fmt.Errorf("invalid config %s: unknown timestamps %q", path, cfg.Timestamps)
+	}
+	if cfg.Clock != "24h" && cfg.Clock != "12h" {
+		return cfg, fmt.Errorf("invalid config %s: clock must be 24h or 12h", path)
+	}
+	tmpl, err := parseMessageTemplate(cfg.MessageTemplate)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid config %s: message_template: %w", path, err)
+	}
+	cfg.messageTemplate = tmpl
+	for i, r := range cfg.Linkifiers {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid config %s: linkifier %d: %w", path, i+1, err)
+		}
+		if r.URL == "" {
+			return cfg, fmt.Errorf("invalid config %s: linkifier %d has no url", path, i+1)
+		}
+		cfg.Linkifiers[i].re = re
+	}
+	awayAfter, err := time.ParseDuration(cfg.AwayAfter)
+	if err != nil || awayAfter < 0 {
+		return cfg, fmt.Errorf("invalid config %s: away_after must be a duration such as 5m", path)
+	}
+	cfg.awayAfter = awayAfter
+	theme, err := loadTheme(cfg.Theme, filepath.Dir(path))
+	if err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	cfg.theme = theme
+	if err := checkKeys(cfg.Keys); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: keys: %w", path, err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMouseIsOptIn(t *testing.T) {
+	tests := []struct {
+		config string
+		mouse  bool
+	}{
+		{`{}`, false},
+		{`{"mouse": false}`, false},
+		{`{"mouse": true}`, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(tt.config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("LoadConfig(%s): %v", tt.config, err)
+		}
+		if cfg.Mouse != tt.mouse {
+			t.Errorf("LoadConfig(%s) mouse = %v, want %v", tt.config, cfg.Mouse, tt.mouse)
+		}
+	}
+}
@@ -2,9 +2,10 @@ package ui
 
 const (
 	// Icons
-	ICON_DONE   = ''
-	ICON_FAILED = ''
-	ICON_DOT    = ''
+	ICON_DONE       = ''
+	ICON_FAILED     = ''
+	ICON_DOT        = ''
+	ICON_ATTACHMENT = ''
 
 	// Status Types
 	STATUS_LOGIN = iota
@@ -19,4 +20,18 @@ const (
 	STATUS_REQUEST_ACTION_SEND
 	STATUS_MESSAGE_RECV
 	STATUS_MESSAGE_SEND
+	STATUS_MESSAGE_FAILED
+	STATUS_DRAFT_EDIT
+	STATUS_ATTACHMENT_UPLOAD
+	STATUS_ATTACHMENT_DOWNLOAD
+	STATUS_PREVIEW_LOAD
+	STATUS_OVERLAYS_DRAW
+	STATUS_CHAT_EXPORT
+	STATUS_PRESENCE_OPEN
+	STATUS_PRESENCE_RECV
+	STATUS_PRESENCE_CLOSE
+	STATUS_PRESENCE_TICK
+	STATUS_TYPING_EXPIRE
+	STATUS_CLOCK_TICK
+	STATUS_GROUP_ACTION_SEND
 )
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/Ayobami0/cli-chat/pb"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -25,6 +26,8 @@ type createModel struct {
 	height          int
 	client          pb.ChatServiceClient
 	authRes         *pb.UserAuthenticatedResponse
+	cfg             Config
+	keys            keyMap
 }
 
 func (m createModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -57,13 +60,12 @@ func (m createModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmd)
 	case tea.KeyMsg:
 		m.setInputsDefaultPlaceholders()
-		switch msg.String() {
-		case "ctrl+c", "esc":
+		switch {
+		case key.Matches(msg, m.keys.Quit, m.keys.Cancel):
 			return m, tea.Quit
-		case "tab", "shift+tab", "enter", "up", "down":
+		case key.Matches(msg, m.keys.NextPanel, m.keys.PrevPanel, m.keys.Enter, m.keys.Up, m.keys.Down):
 			if !m.isCreated && !m.isCreating {
-				s := msg.String()
-				if s == "up" || s == "shift+tab" {
+				if key.Matches(msg, m.keys.Up, m.keys.PrevPanel) {
 					if m.focusedIdx == 0 {
 						m.focusedIdx = len(m.inputs) - 1
 					} else {
@@ -71,7 +73,7 @@ func (m createModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				} else {
 					if m.focusedIdx == len(m.inputs)-1 {
-						if s == "enter" {
+						if key.Matches(msg, m.keys.Enter) {
 							cred := make(map[string]string, len(m.inputs)-1) // credentials for account creation
 							for i := 0; i <= len(m.inputs)-1; i++ {
 								var errTxt string
@@ -117,11 +119,11 @@ func (m createModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				return m, tea.Batch(cmds...)
 			} else if m.isLoggedIn {
-				return enterChat(m.client, m.width, m.height, m.authRes)
+				return enterChat(m.client, m.width, m.height, m.authRes, m.cfg)
 			}
 		default:
 			if m.isLoggedIn && m.isCreated {
-				return enterChat(m.client, m.width, m.height, m.authRes)
+				return enterChat(m.client, m.width, m.height, m.authRes, m.cfg)
 			}
 		}
 	}
@@ -183,7 +185,9 @@ func (m *createModel) setInputsDefaultPlaceholders() {
 	}
 }
 
-func NewCreateModel(client pb.ChatServiceClient) createModel {
+func NewCreateModel(client pb.ChatServiceClient, cfg Config) createModel {
+	applyTheme(cfg.theme)
+
 	// Spinner
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
@@ -212,6 +216,8 @@ func NewCreateModel(client pb.ChatServiceClient) createModel {
 		},
 		spinner: sp,
 		client:  client,
+		cfg:     cfg,
+		keys:    newKeyMap(cfg),
 	}
 
 	return model
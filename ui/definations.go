@@ -22,25 +22,60 @@ func (e errMsg) Error() string { return e.err.Error() }
 type chatItem struct {
 	chatType  pb.ChatType
 	name      string
+	partner   string // the other member of a direct chat
 	id        string
 	maxMember int
 	members   []*pb.User
 	messages  []*pb.Message
+	unread    int // messages received since the chat was last seen
+	mentions  int // unread messages mentioning the current user
+	createdAt time.Time
+	presence  pb.PresenceState        // of the partner of a direct chat
+	reads     map[string]string       // latest message read by each member, by username
+	roles     map[string]pb.GroupRole // of the owner and admins of a group, by username
 }
 
-func (c chatItem) Title() string {
+// Returns the name of a group, or the members of a direct chat
+func (c chatItem) displayName() string {
 	if c.chatType == pb.ChatType_CHAT_TYPE_DIRECT {
 		return fmt.Sprintf("%s + %s", c.members[0].Username, c.members[1].Username)
 	}
 	return c.name
 }
+
+func (c chatItem) Title() string {
+	title := c.displayName()
+	if icon := presenceIcon(c.presence); icon != "" {
+		title = icon + " " + title
+	}
+	if c.mentions > 0 {
+		title += fmt.Sprintf(" @%d", c.mentions)
+	}
+	if c.unread > 0 {
+		title += fmt.Sprintf(" (%d)", c.unread)
+	}
+	return title
+}
 func (c chatItem) Description() string {
 	if len(c.messages) == 0 {
 		return ""
 	}
 	return c.messages[len(c.messages)-1].Content
 }
-func (c chatItem) FilterValue() string { return c.name }
+func (c chatItem) FilterValue() string {
+	if c.chatType == pb.ChatType_CHAT_TYPE_DIRECT {
+		return c.partner
+	}
+	return c.name
+}
+
+// Returns when the latest message of the chat was sent
+func (c chatItem) lastActivity() time.Time {
+	if len(c.messages) == 0 {
+		return time.Time{}
+	}
+	return c.messages[len(c.messages)-1].SentAt.AsTime()
+}
 
 type requestItem struct {
 	name   string
@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	detailsWidth = 26
+	// Lines above the member list: the name, type, creation date, a blank
+	// line and the member count
+	detailsMembersTop = 5
+)
+
+// Shows or hides the details panel
+func (m *chatModel) toggleDetails() tea.Cmd {
+	prefs := m.layout
+	prefs.Details = !prefs.Details
+	prefs.Zen = false
+	cmd := m.setLayout(prefs)
+	if prefs.Details && !m.panes().details {
+		m.msg = "The window is too narrow for the chat details"
+	}
+	return cmd
+}
+
+// Returns the index of the first member shown in a details panel height
+// lines tall, scrolled so the selected member shows
+func (m chatModel) detailsMembersStart(height int) int {
+	rows := max(1, height-detailsMembersTop-1) // the last line is the hint
+	return max(0, m.detailsCursor-rows+1)
+}
+
+// Renders the type, creation date and members of the open chat
+func (m chatModel) formatChatDetails(width, height int) string {
+	_, chat, ok := m.activeChatItem()
+	if !ok {
+		return suggestionStyle.Render("No chat open")
+	}
+
+	kind := "Group"
+	if chat.chatType == pb.ChatType_CHAT_TYPE_DIRECT {
+		kind = "Direct chat"
+		if presence := m.formatPresence(chat.partner); presence != "" {
+			kind = presence
+		}
+	}
+	created := "Created —"
+	if !chat.createdAt.IsZero() {
+		created = "Created " + chat.createdAt.Local().Format("2 Jan 2006")
+	}
+	lines := []string{
+		senderTextStyle.Copy().Bold(true).Render(runewidth.Truncate(chat.displayName(), width, "…")),
+		suggestionStyle.Render(kind),
+		suggestionStyle.Render(created),
+		"",
+		fmt.Sprintf("Members (%d)", len(chat.members)),
+	}
+
+	start := m.detailsMembersStart(height)
+	rows := max(1, height-detailsMembersTop-1)
+	for i := start; i < len(chat.members) && i < start+rows; i++ {
+		name := chat.members[i].Username
+		if name == m.user.Username {
+			name += " (you)"
+		}
+		role := roleName(chat.role(chat.members[i].Username))
+		if role != "" {
+			role = " " + role
+		}
+		// Room is kept for the cursor, the presence icon and the role
+		name = runewidth.Truncate(name, width-4-len(role), "…")
+		icon := m.formatPresenceIcon(chat.members[i].Username)
+		if icon == "" {
+			icon = " "
+		}
+		if i == m.detailsCursor && m.focusedPanel == DETAILS_PANEL {
+			lines = append(lines, selectedSuggestionStyle.Render("> ")+icon+" "+selectedSuggestionStyle.Render(name)+suggestionDescStyle.Render(role))
+		} else {
+			lines = append(lines, "  "+icon+" "+userStyle(chat.members[i].Username).Render(name)+suggestionDescStyle.Render(role))
+		}
+	}
+
+	view := strings.Join(lines, "\n")
+	if m.focusedPanel == DETAILS_PANEL {
+		hint := suggestionDescStyle.Render(m.keys.Enter.Help().Key + " message")
+		view = lipgloss.PlaceVertical(height-1, lipgloss.Top, view) + "\n" + hint
+	}
+	return view
+}
+
+// Moves the member cursor of the details panel
+func (m *chatModel) moveDetailsCursor(up bool) {
+	_, chat, ok := m.activeChatItem()
+	if !ok {
+		return
+	}
+	if up && m.detailsCursor > 0 {
+		m.detailsCursor--
+	} else if !up && m.detailsCursor < len(chat.members)-1 {
+		m.detailsCursor++
+	}
+}
+
+// Returns the member at line y of the details panel, or -1 if there is none
+func (m chatModel) detailsMemberAt(y int) int {
+	_, chat, ok := m.activeChatItem()
+	if !ok || y < detailsMembersTop {
+		return -1
+	}
+	i := m.detailsMembersStart(m.bodyHeight()-2) + y - detailsMembersTop
+	if i >= len(chat.members) {
+		return -1
+	}
+	return i
+}
+
+// Opens the direct chat with the selected member, sending them a chat request
+// if there is none yet
+func (m *chatModel) messageMember() tea.Cmd {
+	_, chat, ok := m.activeChatItem()
+	if !ok || m.detailsCursor >= len(chat.members) {
+		return nil
+	}
+	username := chat.members[m.detailsCursor].Username
+	if username == m.user.Username {
+		return nil
+	}
+	return m.directChat(username)
+}
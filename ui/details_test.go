@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var altSeven = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("7"), Alt: true}
+
+func detailsTestModel(shown bool) chatModel {
+	m := newTestChatModel(
+		chatItem{id: "g", name: "g", chatType: pb.ChatType_CHAT_TYPE_GROUP, members: []*pb.User{{Username: "me"}, {Username: "alice"}}},
+		chatItem{id: "d", chatType: pb.ChatType_CHAT_TYPE_DIRECT, partner: "alice", members: []*pb.User{{Username: "me"}, {Username: "alice"}}},
+	)
+	m.client = &fakeChatClient{stream: &fakeChatStream{}}
+	m.activeChat = "g"
+	m.focusedPanel = CHATS_PANEL
+	m.layout.Details = shown
+	return m
+}
+
+func TestFocusDetailsKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		shown bool
+		want  int
+	}{
+		{"shown", true, DETAILS_PANEL},
+		{"hidden", false, CHATS_PANEL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, _ := detailsTestModel(tt.shown).Update(altSeven)
+			if got := model.(chatModel).focusedPanel; got != tt.want {
+				t.Errorf("focused panel %d after alt+7, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFocusDetailsKeyInHelp(t *testing.T) {
+	help := newKeyMap(DefaultConfig()).SwitchPanel.Help()
+	if help.Key != "alt+[n]" || !strings.Contains(help.Desc, "7|chat details") {
+		t.Errorf("switch panel help %q %q does not list alt+7", help.Key, help.Desc)
+	}
+}
+
+func TestMessageMemberFromDetails(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []tea.KeyMsg
+		active string
+	}{
+		{"self", []tea.KeyMsg{{Type: tea.KeyEnter}}, "g"},
+		{"member", []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyEnter}}, "d"},
+		{"past the last member", []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyDown}, {Type: tea.KeyEnter}}, "d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, _ := detailsTestModel(true).Update(altSeven)
+			for _, k := range tt.keys {
+				model, _ = model.Update(k)
+			}
+			if got := model.(chatModel).activeChat; got != tt.active {
+				t.Errorf("open chat %q, want %q", got, tt.active)
+			}
+		})
+	}
+}
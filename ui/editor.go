@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Returns the command used to edit the file at path. $VISUAL and $EDITOR may
+// include arguments i.e. "code --wait".
+func editorCmd(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	return exec.Command(args[0], append(args[1:], path)...)
+}
+
+// Opens draft in the user's editor. The edited text is returned once the
+// editor exits.
+func editDraft(draft string) tea.Cmd {
+	f, err := os.CreateTemp("", "cli-chat-*.md")
+	if err != nil {
+		return func() tea.Msg { return errMsg{err} }
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(draft); err != nil {
+		os.Remove(f.Name())
+		return func() tea.Msg { return errMsg{err} }
+	}
+
+	return tea.ExecProcess(editorCmd(f.Name()), func(err error) tea.Msg {
+		defer os.Remove(f.Name())
+		if err != nil {
+			return errMsg{err}
+		}
+
+		b, err := os.ReadFile(f.Name())
+		if err != nil {
+			return errMsg{err}
+		}
+		return statusMsg{sType: STATUS_DRAFT_EDIT, sRes: strings.TrimRight(string(b), "\n")}
+	})
+}
+
+// Number of rows needed to show the input without scrolling
+func inputRows(value string, width int) int {
+	width = max(1, width)
+	rows := 0
+	for _, line := range strings.Split(value, "\n") {
+		rows += max(1, (lipgloss.Width(line)+width-1)/width)
+	}
+	return rows
+}
@@ -0,0 +1,29 @@
+package ui
+
+import "testing"
+
+func TestInputRows(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		width int
+		want  int
+	}{
+		{"empty", "", 10, 1},
+		{"fits", "hello", 10, 1},
+		{"exactly the width", "0123456789", 10, 1},
+		{"wraps", "01234567890", 10, 2},
+		{"lines", "a\nb\nc", 10, 3},
+		{"empty lines", "a\n\n", 10, 3},
+		{"wide characters", "日本語日本語", 10, 2},
+		{"wrapped line among lines", "a\n0123456789012345678901", 10, 4},
+		{"no width", "abc", 0, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inputRows(tt.value, tt.width); got != tt.want {
+				t.Errorf("inputRows(%q, %d) = %d, want %d", tt.value, tt.width, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kyokomi/emoji/v2"
+	"github.com/sahilm/fuzzy"
+)
+
+const (
+	// Number of characters typed after ':' before all emojis are suggested.
+	// Fewer only match the recently used ones.
+	minEmojiQuery       = 2
+	maxRecentEmoji      = 10
+	maxEmojiSuggestions = 50
+)
+
+var shortcodePattern = regexp.MustCompile(`:([a-zA-Z0-9_+\-]+):`)
+
+// Emojis by shortcode without the colons, and the sorted shortcodes
+var emojiCodes, emojiNames = loadEmojiTable()
+
+func loadEmojiTable() (map[string]string, []string) {
+	codes := map[string]string{}
+	var names []string
+	for code, e := range emoji.CodeMap() {
+		name := strings.Trim(code, ":")
+		codes[name] = e
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return codes, names
+}
+
+// Replaces the known :shortcodes: in s with their emoji. Code spans are left
+// as they are.
+func expandShortcodes(s string) string {
+	parts := strings.Split(s, "`")
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = shortcodePattern.ReplaceAllStringFunc(parts[i], func(code string) string {
+			if e, ok := emojiCodes[strings.Trim(code, ":")]; ok {
+				return e
+			}
+			return code
+		})
+	}
+	return strings.Join(parts, "`")
+}
+
+// Suggests emojis for the :shortcode being typed. Recently used emojis are
+// listed first. A lone ':' suggests nothing, so enter still sends it.
+func (m chatModel) emojiSuggestions(word string) []suggestion {
+	query := strings.TrimPrefix(word, ":")
+	if query == "" || strings.ContainsAny(query, ": ") {
+		return nil
+	}
+
+	var names []string
+	switch {
+	case len(query) < minEmojiQuery:
+		for _, name := range m.recentEmoji {
+			if strings.HasPrefix(name, query) {
+				names = append(names, name)
+			}
+		}
+	default:
+		recent := map[string]bool{}
+		for _, name := range m.recentEmoji {
+			if strings.Contains(name, query) {
+				names = append(names, name)
+				recent[name] = true
+			}
+		}
+		// Shortcodes starting with the query are the likeliest matches
+		matches := fuzzy.Find(query, emojiNames)
+		sort.SliceStable(matches, func(i, j int) bool {
+			return strings.HasPrefix(matches[i].Str, query) && !strings.HasPrefix(matches[j].Str, query)
+		})
+		for _, match := range matches {
+			if len(names) == maxEmojiSuggestions {
+				break
+			}
+			if !recent[match.Str] {
+				names = append(names, match.Str)
+			}
+		}
+	}
+
+	var items []suggestion
+	for _, name := range names {
+		items = append(items, suggestion{value: emojiCodes[name], desc: ":" + name + ":"})
+	}
+	return items
+}
+
+// Moves an emoji to the front of the recently used emojis
+func (m *chatModel) useEmoji(name string) tea.Cmd {
+	recent := []string{name}
+	for _, v := range m.recentEmoji {
+		if v != name && len(recent) < maxRecentEmoji {
+			recent = append(recent, v)
+		}
+	}
+	m.recentEmoji = recent
+
+	return saveRecentEmoji(recent)
+}
+
+// Expands the :shortcode: directly before the cursor, if one was just closed
+func (m *chatModel) expandShortcodeBeforeCursor() tea.Cmd {
+	word := wordBeforeCursor(m.input)
+	loc := shortcodePattern.FindStringSubmatchIndex(word)
+	if loc == nil || loc[1] != len(word) {
+		return nil
+	}
+	name := word[loc[2]:loc[3]]
+	e, ok := emojiCodes[name]
+	if !ok {
+		return nil
+	}
+	replaceBeforeCursor(&m.input, len([]rune(word[loc[0]:])), e)
+
+	return m.useEmoji(name)
+}
+
+func recentEmojiPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cli-chat", "recent_emoji"), nil
+}
+
+// Returns the recently used emojis saved by previous sessions
+func loadRecentEmoji() []string {
+	path, err := recentEmojiPath()
+	if err != nil {
+		return nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var recent []string
+	for _, name := range strings.Fields(string(b)) {
+		if _, ok := emojiCodes[name]; ok && len(recent) < maxRecentEmoji {
+			recent = append(recent, name)
+		}
+	}
+	return recent
+}
+
+func saveRecentEmoji(recent []string) tea.Cmd {
+	return func() tea.Msg {
+		// Losing the recently used emojis is not worth interrupting the user
+		path, err := recentEmojiPath()
+		if err != nil {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil
+		}
+		os.WriteFile(path, []byte(strings.Join(recent, "\n")+"\n"), 0644)
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestExpandShortcodes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"no codes", "no codes"},
+		{":smile:", emojiCodes["smile"]},
+		{"hi :wave: there", "hi " + emojiCodes["wave"] + " there"},
+		{":not_an_emoji:", ":not_an_emoji:"},
+		{"time 10:30:45", "time 10:30:45"},
+		{"`:smile:` :smile:", "`:smile:` " + emojiCodes["smile"]},
+		{"a ` b :smile:", "a ` b :smile:"},
+		{":smile::smile:", emojiCodes["smile"] + emojiCodes["smile"]},
+	}
+	for _, tt := range tests {
+		if got := expandShortcodes(tt.in); got != tt.want {
+			t.Errorf("expandShortcodes(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEmojiSuggestions(t *testing.T) {
+	m := newTestChatModel()
+	m.recentEmoji = []string{"sob", "smile", "tada"}
+
+	values := func(items []suggestion) []string {
+		var v []string
+		for _, s := range items {
+			v = append(v, s.desc)
+		}
+		return v
+	}
+	tests := []struct {
+		word string
+		want []string // the first suggestions
+	}{
+		{":", nil},
+		{":s", []string{":sob:", ":smile:"}},
+		{":x", nil},
+		{":smi", []string{":smile:"}},
+		{":smile:", nil},
+		{":a b", nil},
+	}
+	for _, tt := range tests {
+		got := values(m.emojiSuggestions(tt.word))
+		if len(got) > len(tt.want) {
+			got = got[:len(tt.want)]
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("emojiSuggestions(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestColonIsSentOnEnter(t *testing.T) {
+	m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.activeChat = "a"
+	m.setChatStream(&fakeChatStream{})
+	m.focusedPanel = MESSAGE_PANEL
+	m.recentEmoji = []string{"smile"}
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(":")})
+	m = model.(chatModel)
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(chatModel)
+	if len(m.pending) != 1 || m.pending[0].msg.Content != ":" {
+		t.Fatalf("':' was not sent, input %q", m.input.Value())
+	}
+}
+
+func TestShortcodeExpandedWhenClosed(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []tea.KeyMsg
+		want string
+	}{
+		{"typed", []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune(":tada")}, {Type: tea.KeyRunes, Runes: []rune(":")}}, emojiCodes["tada"]},
+		{"pasted", []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("hi :tada:"), Paste: true}}, "hi " + emojiCodes["tada"]},
+		{"unknown", []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune(":nope:")}}, ":nope:"},
+		{"deleted back to a shortcode", []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune(":tada:x")}, {Type: tea.KeyBackspace}}, ":tada:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestChatModel()
+			m.focusedPanel = MESSAGE_PANEL
+			m.recentEmoji = nil
+			for _, k := range tt.keys {
+				model, _ := m.Update(k)
+				m = model.(chatModel)
+			}
+			if got := m.input.Value(); got != tt.want {
+				t.Errorf("input = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Replaces the characters not allowed in file names
+var fileNameReplacer = strings.NewReplacer("/", "-", "\\", "-", ":", "-", "*", "", "?", "", "\"", "", "<", "", ">", "", "|", "")
+
+// Formats the messages of a chat as a markdown document. Tokens matched by
+// the linkifier rules become links.
+func formatExport(chat chatItem, rules []LinkRule) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\nExported on %s\n", chat.displayName(), time.Now().Format("2006-01-02 15:04"))
+
+	for _, msg := range chat.messages {
+		sentAt := msg.SentAt.AsTime().Local().Format("2006-01-02 15:04")
+		switch msg.Type {
+		case pb.Message_MESSAGE_TYPE_REGULAR:
+			fmt.Fprintf(&b, "\n**%s** %s\n\n%s\n", msg.Sender.GetUsername(), sentAt, markdownLinks(msg.Content, rules))
+		case pb.Message_MESSAGE_TYPE_ATTACHMENT:
+			fmt.Fprintf(&b, "\n**%s** %s\n\n%c %s (%s)\n", msg.Sender.GetUsername(), sentAt, ICON_ATTACHMENT, msg.Attachment.GetName(), humanSize(msg.Attachment.GetSize()))
+		case pb.Message_MESSAGE_TYPE_NOTIFICATION:
+			fmt.Fprintf(&b, "\n_%s_ %s\n", msg.Content, sentAt)
+		}
+	}
+	return b.String()
+}
+
+// Writes a chat to a markdown file in dir
+func (c chatModel) exportChat(chat chatItem, dir string) tea.Cmd {
+	return func() tea.Msg {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return errMsg{err}
+		}
+
+		name := fmt.Sprintf("%s %s.md", fileNameReplacer.Replace(chat.displayName()), time.Now().Format("2006-01-02"))
+		path, err := availablePath(dir, name)
+		if err != nil {
+			return errMsg{err}
+		}
+		if err := os.WriteFile(path, []byte(formatExport(chat, c.cfg.Linkifiers)), 0644); err != nil {
+			return errMsg{err}
+		}
+
+		return statusMsg{sType: STATUS_CHAT_EXPORT, sRes: path}
+	}
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+)
+
+func TestExportLinksOnlyMessageContent(t *testing.T) {
+	rules := []LinkRule{
+		{URL: "https://jira.example.com/browse/$0", re: regexp.MustCompile(`\bPROJ-\d+\b`)},
+		// Would match the years of the timestamps
+		{URL: "https://build.example.com/$0", re: regexp.MustCompile(`\b\d{4}\b`)},
+	}
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	msg := testMessage("2", "alice", at)
+	msg.Content = "PROJ-1 broke build 1234, see https://example.com"
+	notice := testMessage("3", "", at)
+	notice.Type, notice.Content = pb.Message_MESSAGE_TYPE_NOTIFICATION, "PROJ-8 joined"
+	chat := chatItem{
+		id:       "a",
+		name:     "PROJ-9",
+		chatType: pb.ChatType_CHAT_TYPE_GROUP,
+		messages: []*pb.Message{
+			testMessage("1", "PROJ-7", at),
+			msg,
+			notice,
+		},
+	}
+
+	export := formatExport(chat, rules)
+	for _, want := range []string{
+		"[PROJ-1](https://jira.example.com/browse/PROJ-1)",
+		"[1234](https://build.example.com/1234)",
+		"see https://example.com",
+		"**PROJ-7**",
+		"# PROJ-9",
+	} {
+		if !strings.Contains(export, want) {
+			t.Errorf("export does not contain %q:\n%s", want, export)
+		}
+	}
+	if n := strings.Count(export, "]("); n != 2 {
+		t.Errorf("export has %d links, want 2:\n%s", n, export)
+	}
+}
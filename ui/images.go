@@ -0,0 +1,365 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	IMAGE_PROTOCOL_NONE = iota
+	IMAGE_PROTOCOL_BLOCKS
+	IMAGE_PROTOCOL_KITTY
+	IMAGE_PROTOCOL_SIXEL
+
+	// Largest image attachment downloaded for a preview
+	maxPreviewSize = 10 * 1024 * 1024
+	// Largest image decoded for a preview. A small file can declare a huge
+	// image, so its size is checked before it is decoded.
+	maxPreviewPixels = 25 * 1000 * 1000
+	// Previews are never taller than this many rows
+	maxPreviewRows = 20
+
+	// Used when the terminal does not report its size in pixels
+	defaultCellWidth  = 10
+	defaultCellHeight = 20
+
+	// Sixel images and hyperlinks are drawn once bubbletea has rendered the
+	// view, if the rows under them were repainted
+	overlayDrawDelay = 50 * time.Millisecond
+)
+
+type imagePreview struct {
+	img      image.Image
+	failed   bool
+	kittyID  uint32
+	sixel    string
+	cols     int
+	rows     int
+	rendered string
+}
+
+// Previews of the image attachments of the open chat
+type imagePreviews struct {
+	protocol int
+	previews map[string]*imagePreview // by attachment id
+	nextID   uint32
+	output   string // kitty sequences waiting to be written to the terminal
+}
+
+// Position of a sixel image in the viewport content
+type sixelPlacement struct {
+	id   string
+	line int
+	col  int
+}
+
+type previewResult struct {
+	id  string
+	img image.Image
+	err error
+}
+
+func newImagePreviews(setting string) *imagePreviews {
+	return &imagePreviews{
+		protocol: detectImageProtocol(setting),
+		previews: map[string]*imagePreview{},
+	}
+}
+
+// Picks how images are drawn. Graphics protocols cannot pass through tmux, so
+// block art is used there.
+func detectImageProtocol(setting string) int {
+	switch setting {
+	case "off":
+		return IMAGE_PROTOCOL_NONE
+	case "blocks":
+		return IMAGE_PROTOCOL_BLOCKS
+	case "kitty":
+		return IMAGE_PROTOCOL_KITTY
+	case "sixel":
+		return IMAGE_PROTOCOL_SIXEL
+	}
+
+	// Block art is nothing but colour
+	blocks := IMAGE_PROTOCOL_BLOCKS
+	if noColor() {
+		blocks = IMAGE_PROTOCOL_NONE
+	}
+
+	term, program := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("TMUX") != "" || strings.HasPrefix(term, "screen"):
+		return blocks
+	case term == "xterm-kitty" || os.Getenv("KITTY_WINDOW_ID") != "" || program == "ghostty":
+		return IMAGE_PROTOCOL_KITTY
+	case program == "WezTerm" || strings.Contains(term, "foot") || strings.Contains(term, "mlterm") || strings.Contains(term, "sixel"):
+		return IMAGE_PROTOCOL_SIXEL
+	}
+	return blocks
+}
+
+func (p *imagePreviews) previewable(att *pb.Attachment) bool {
+	return p.protocol != IMAGE_PROTOCOL_NONE &&
+		att != nil && att.Id != "" &&
+		strings.HasPrefix(att.MimeType, "image/") &&
+		att.Size <= maxPreviewSize
+}
+
+// Checks if the preview of an attachment is ready to be drawn
+func (p *imagePreviews) ready(id string) bool {
+	pv, ok := p.previews[id]
+	return ok && pv.img != nil
+}
+
+func (p *imagePreviews) loaded(res previewResult) {
+	pv, ok := p.previews[res.id]
+	if !ok {
+		return
+	}
+	if res.err != nil {
+		pv.failed = true
+		return
+	}
+	pv.img = res.img
+
+	if p.protocol == IMAGE_PROTOCOL_KITTY {
+		p.nextID++
+		pv.kittyID = p.nextID
+		seq, err := kittyTransmit(pv.kittyID, pv.img)
+		if err != nil {
+			pv.failed = true
+			pv.img = nil
+			return
+		}
+		p.output += seq
+	}
+}
+
+// Writes the kitty sequences of the previews loaded or resized since the last
+// call to the terminal
+func (p *imagePreviews) flush() tea.Cmd {
+	cmd := writeTerminal(p.output)
+	p.output = ""
+	return cmd
+}
+
+// Size of a preview in cells, fitting the given width while keeping the
+// aspect ratio of the image
+func previewSize(img image.Image, width int) (int, int) {
+	cellW, cellH := cellSize()
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return 1, 1
+	}
+
+	cols := max(1, min(width, b.Dx()/cellW))
+	rows := max(1, cols*b.Dy()*cellW/(b.Dx()*cellH))
+	if rows > maxPreviewRows {
+		rows = maxPreviewRows
+		cols = max(1, rows*b.Dx()*cellH/(b.Dy()*cellW))
+	}
+	return cols, rows
+}
+
+// Renders the preview of an attachment to fit width. Returns an empty string
+// if the preview is not loaded.
+func (p *imagePreviews) render(id string, width int) string {
+	pv, ok := p.previews[id]
+	if !ok || pv.img == nil || width < 1 {
+		return ""
+	}
+
+	cols, rows := previewSize(pv.img, width)
+	if pv.rendered != "" && cols == pv.cols && rows == pv.rows {
+		return pv.rendered
+	}
+	pv.cols, pv.rows = cols, rows
+
+	switch p.protocol {
+	case IMAGE_PROTOCOL_KITTY:
+		p.output += kittyPlace(pv.kittyID, cols, rows)
+		pv.rendered = kittyPlaceholders(pv.kittyID, cols, rows)
+	case IMAGE_PROTOCOL_SIXEL:
+		// The image is drawn over blank lines reserved for it
+		cellW, cellH := cellSize()
+		pv.sixel = encodeSixel(scaleImage(pv.img, cols*cellW, rows*cellH))
+		pv.rendered = strings.TrimSuffix(strings.Repeat(strings.Repeat(" ", cols)+"\n", rows), "\n")
+	default:
+		pv.rendered = halfBlocks(scaleImage(pv.img, cols, rows*2))
+	}
+	return pv.rendered
+}
+
+// Returns the sixel images fully inside the viewport and the screen rows they
+// cover. x and y are the screen position of the viewport.
+func (p *imagePreviews) sixelOverlay(placements []sixelPlacement, yOffset, height, x, y int) (string, []int) {
+	var b strings.Builder
+	var rows []int
+	for _, pl := range placements {
+		pv, ok := p.previews[pl.id]
+		if !ok || pv.sixel == "" || pl.line < yOffset || pl.line+pv.rows > yOffset+height {
+			continue
+		}
+		// Save the cursor as bubbletea expects it to be where it left it
+		fmt.Fprintf(&b, "\x1b7\x1b[%d;%dH%s\x1b8", y+pl.line-yOffset+1, x+pl.col+1, pv.sixel)
+		for r := 0; r < pv.rows; r++ {
+			rows = append(rows, y+pl.line-yOffset+r)
+		}
+	}
+	return b.String(), rows
+}
+
+// Scales img to w x h pixels, averaging the pixels each one covers
+func scaleImage(img image.Image, w, h int) image.Image {
+	b := img.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/h)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/w)
+
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBAModel.Convert(img.At(sx, sy)).(color.NRGBA)
+					r, g, bl, a = r+uint32(c.R), g+uint32(c.G), bl+uint32(c.B), a+uint32(c.A)
+					n++
+				}
+			}
+			out.SetNRGBA(x, y, color.NRGBA{uint8(r / n), uint8(g / n), uint8(bl / n), uint8(a / n)})
+		}
+	}
+	return out
+}
+
+// Renders img with upper half blocks, each cell showing two pixels
+func halfBlocks(img image.Image) string {
+	b := img.Bounds()
+	toColor := func(x, y int) lipgloss.TerminalColor {
+		if y >= b.Max.Y {
+			return lipgloss.NoColor{}
+		}
+		c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+		if c.A < 128 {
+			return lipgloss.NoColor{}
+		}
+		return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+	}
+
+	var lines []string
+	for y := b.Min.Y; y < b.Max.Y; y += 2 {
+		var line strings.Builder
+		for x := b.Min.X; x < b.Max.X; x++ {
+			line.WriteString(defaultStyle.Copy().Foreground(toColor(x, y)).Background(toColor(x, y+1)).Render("▀"))
+		}
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Returns the commands loading the previews of image attachments in msgs that
+// have not been requested yet
+func (c chatModel) loadPreviews(msgs []*pb.Message) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, msg := range msgs {
+		att := msg.Attachment
+		if !c.previews.previewable(att) {
+			continue
+		}
+		if _, ok := c.previews.previews[att.Id]; ok {
+			continue
+		}
+		c.previews.previews[att.Id] = &imagePreview{}
+		cmds = append(cmds, c.loadPreview(att))
+	}
+	return tea.Batch(cmds...)
+}
+
+// Decodes an image attachment, downloading it to the cache if needed
+func (c chatModel) loadPreview(att *pb.Attachment) tea.Cmd {
+	return func() tea.Msg {
+		path, err := c.cachedAttachment(att)
+		if err != nil {
+			return statusMsg{sType: STATUS_PREVIEW_LOAD, sRes: previewResult{id: att.Id, err: err}}
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return statusMsg{sType: STATUS_PREVIEW_LOAD, sRes: previewResult{id: att.Id, err: err}}
+		}
+		defer f.Close()
+
+		img, err := decodePreview(f)
+		return statusMsg{sType: STATUS_PREVIEW_LOAD, sRes: previewResult{id: att.Id, img: img, err: err}}
+	}
+}
+
+// Decodes an image unless it is larger than maxPreviewPixels
+func decodePreview(r io.ReadSeeker) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxPreviewPixels {
+		return nil, fmt.Errorf("image of %dx%d pixels is too large to preview", cfg.Width, cfg.Height)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+func (c chatModel) cachedAttachment(att *pb.Attachment) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "cli-chat", "attachments")
+	// The id and name come from the sender
+	id, err := cleanFileName(att.Id)
+	if err != nil || id != att.Id {
+		return "", fmt.Errorf("invalid attachment id %q", att.Id)
+	}
+	path, err := pathInDir(dir, id+filepath.Ext(att.Name))
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	meta := metadata.Pairs("authorization", fmt.Sprintf("Bearer %s", c.sessionToken))
+
+	ctx := metadata.NewOutgoingContext(c.session, meta)
+
+	if err := c.fetchAttachment(ctx, att, path+".part"); err != nil {
+		return "", err
+	}
+	return path, os.Rename(path+".part", path)
+}
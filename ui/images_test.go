@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+)
+
+func TestPreviewSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		w, h       int
+		width      int
+		cols, rows int
+	}{
+		{"fits the width", 1000, 500, 40, 40, 10},
+		{"smaller than the width", 100, 100, 40, 10, 5},
+		{"capped height", 100, 4000, 40, 1, 20},
+		{"zero width", 0, 100, 40, 1, 1},
+		{"zero height", 100, 0, 40, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, rows := previewSize(image.NewNRGBA(image.Rect(0, 0, tt.w, tt.h)), tt.width)
+			if cols != tt.cols || rows != tt.rows {
+				t.Errorf("previewSize = %dx%d, want %dx%d", cols, rows, tt.cols, tt.rows)
+			}
+		})
+	}
+}
+
+func TestCachedAttachmentStaysInCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		name string
+		att  *pb.Attachment
+	}{
+		{"id with a parent", &pb.Attachment{Id: "../../evil", Name: "a.png"}},
+		{"id with a directory", &pb.Attachment{Id: "dir/evil", Name: "a.png"}},
+		{"parent id", &pb.Attachment{Id: "..", Name: "a.png"}},
+		{"empty id", &pb.Attachment{Id: "", Name: "a.png"}},
+	}
+	var m chatModel
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if path, err := m.cachedAttachment(tt.att); err == nil {
+				t.Errorf("cachedAttachment(%q) = %q, want an error", tt.att.Id, path)
+			}
+		})
+	}
+}
+
+func TestOverlaysRedrawnWhenRowsChange(t *testing.T) {
+	var o overlayState
+	steps := []struct {
+		name  string
+		frame string
+		rows  []int
+		dirty bool
+	}{
+		{"first frame", "a\nb\nc", []int{1}, true},
+		{"same rows", "a\nb\nc", []int{1}, false},
+		{"other row changed", "x\nb\nc", []int{1}, false},
+		{"row repainted", "x\ny\nc", []int{1}, true},
+		{"overlay moved", "x\ny\ny", []int{2}, true},
+		{"no overlays", "x\ny\ny", nil, true},
+	}
+	for _, s := range steps {
+		o.rendered(s.frame, s.rows)
+		if o.dirty != s.dirty {
+			t.Errorf("%s: dirty = %v, want %v", s.name, o.dirty, s.dirty)
+		}
+		o.dirty = false
+	}
+}
+
+func TestOverlaysFollowUpdatesNotViews(t *testing.T) {
+	m := newTestChatModel(chatItem{
+		id:       "a",
+		name:     "a",
+		chatType: pb.ChatType_CHAT_TYPE_GROUP,
+		messages: []*pb.Message{testMessage("see PROJ-1", "alice", time.Now())},
+	})
+	m.activeChat = "a"
+	m.cfg.Linkifiers = []LinkRule{{URL: "https://jira.example.com/browse/$0", re: regexp.MustCompile(`\bPROJ-\d+\b`)}}
+	m.renderMessages()
+
+	m.View()
+	if m.overlays.dirty || m.overlays.rows != "" {
+		t.Fatal("rendering the view changed the overlays")
+	}
+
+	model, _ := m.Update(statusMsg{sType: STATUS_TYPING_EXPIRE})
+	m = model.(chatModel)
+	if !m.overlays.dirty {
+		t.Fatal("the rows under the link were not followed after an update")
+	}
+	model, cmd := m.Update(statusMsg{sType: STATUS_OVERLAYS_DRAW})
+	m = model.(chatModel)
+	if cmd == nil || m.overlays.dirty {
+		t.Error("the link was not drawn with a command")
+	}
+
+	model, _ = m.Update(statusMsg{sType: STATUS_TYPING_EXPIRE})
+	if model.(chatModel).overlays.dirty {
+		t.Error("the link is drawn again though its row was not repainted")
+	}
+}
+
+func TestKittyOutputWrittenByCommand(t *testing.T) {
+	p := &imagePreviews{protocol: IMAGE_PROTOCOL_KITTY, previews: map[string]*imagePreview{"a": {}}}
+	p.loaded(previewResult{id: "a", img: image.NewNRGBA(image.Rect(0, 0, 20, 20))})
+	p.render("a", 10)
+	if p.output == "" {
+		t.Fatal("nothing to write for a kitty preview")
+	}
+	if p.flush() == nil || p.output != "" {
+		t.Error("the kitty sequences were not handed to a command")
+	}
+	if p.flush() != nil {
+		t.Error("the kitty sequences were written twice")
+	}
+}
+
+// Returns the start of a PNG file declaring a w x h image
+func pngHeader(w, h uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], w)
+	binary.BigEndian.PutUint32(ihdr[8:], h)
+	ihdr[12], ihdr[13] = 8, 6 // 8 bit RGBA
+
+	b := []byte("\x89PNG\r\n\x1a\n")
+	b = binary.BigEndian.AppendUint32(b, 13)
+	b = append(b, ihdr...)
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestDecodePreview(t *testing.T) {
+	var small bytes.Buffer
+	png.Encode(&small, image.NewNRGBA(image.Rect(0, 0, 4, 3)))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"small", small.Bytes(), false},
+		{"declares a huge image", pngHeader(100000, 100000), true},
+		{"over the pixel limit", pngHeader(maxPreviewPixels/1000+1, 1000), true},
+		{"not an image", []byte("hello"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := decodePreview(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodePreview error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && img.Bounds().Dx() != 4 {
+				t.Errorf("decoded %v", img.Bounds())
+			}
+		})
+	}
+}
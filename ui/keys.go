@@ -0,0 +1,313 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// Where a key is handled. Keys of the same scope cannot be bound to more than
+// one action.
+const (
+	KEY_SCOPE_GLOBAL int = iota // everywhere
+	KEY_SCOPE_MOVE              // everywhere, including the pickers
+	KEY_SCOPE_CHATS
+	KEY_SCOPE_REQUESTS
+	KEY_SCOPE_INPUT
+	KEY_SCOPE_VIEW
+	KEY_SCOPE_LINKS
+	KEY_SCOPE_COMPLETION // only while suggestions are shown
+	KEY_SCOPE_NORMAL     // vim normal mode
+)
+
+type keyMap struct {
+	Up            key.Binding
+	Down          key.Binding
+	Left          key.Binding
+	Right         key.Binding
+	Enter         key.Binding
+	Cancel        key.Binding
+	Help          key.Binding
+	Quit          key.Binding
+	Palette       key.Binding
+	NextPanel     key.Binding
+	PrevPanel     key.Binding
+	FocusChats    key.Binding
+	FocusReqs     key.Binding
+	FocusSendReq  key.Binding
+	FocusJoin     key.Binding
+	FocusInput    key.Binding
+	FocusView     key.Binding
+	GrowSidebar   key.Binding
+	ShrinkSidebar key.Binding
+	ToggleSidebar key.Binding
+	Zen           key.Binding
+	ToggleDetails key.Binding
+	FocusDetails  key.Binding
+	Accept        key.Binding
+	Reject        key.Binding
+	Filter        key.Binding
+	React         key.Binding
+	Reactors      key.Binding
+	RawToggle     key.Binding
+	Download      key.Binding
+	Export        key.Binding
+	Delete        key.Binding
+	NewLine       key.Binding
+	Editor        key.Binding
+	Complete      key.Binding
+	Links         key.Binding
+	CopyLink      key.Binding
+	Search        key.Binding
+	NextMatch     key.Binding
+	PrevMatch     key.Binding
+	Insert        key.Binding
+	NormalDown    key.Binding
+	NormalUp      key.Binding
+	Top           key.Binding
+	Bottom        key.Binding
+
+	// Only shown in the help
+	SwitchPanel key.Binding
+	Tab         key.Binding
+}
+
+type keyAction struct {
+	name    string   // name of the action in the config file
+	keys    []string // default keys
+	desc    string
+	scope   int
+	binding func(k *keyMap) *key.Binding
+}
+
+// Every action that can be bound to a key, in the order they are documented
+var keyActions = []keyAction{
+	{"up", []string{"up"}, "move up", KEY_SCOPE_MOVE, func(k *keyMap) *key.Binding { return &k.Up }},
+	{"down", []string{"down"}, "move down", KEY_SCOPE_MOVE, func(k *keyMap) *key.Binding { return &k.Down }},
+	{"left", []string{"left"}, "move left", KEY_SCOPE_MOVE, func(k *keyMap) *key.Binding { return &k.Left }},
+	{"right", []string{"right"}, "move right", KEY_SCOPE_MOVE, func(k *keyMap) *key.Binding { return &k.Right }},
+	{"select", []string{"enter"}, "enter room/send message", KEY_SCOPE_MOVE, func(k *keyMap) *key.Binding { return &k.Enter }},
+	{"cancel", []string{"esc"}, "close", KEY_SCOPE_MOVE, func(k *keyMap) *key.Binding { return &k.Cancel }},
+	{"help", []string{"?"}, "toggle help", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.Help }},
+	{"quit", []string{"ctrl+c"}, "quit", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.Quit }},
+	{"palette", []string{"ctrl+p"}, "command palette", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.Palette }},
+	{"next_panel", []string{"tab"}, "next panel", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.NextPanel }},
+	{"prev_panel", []string{"shift+tab"}, "previous panel", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.PrevPanel }},
+	{"focus_chats", []string{"alt+1"}, "chats", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.FocusChats }},
+	{"focus_requests", []string{"alt+2"}, "requests", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.FocusReqs }},
+	{"focus_send_request", []string{"alt+3"}, "send request", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.FocusSendReq }},
+	{"focus_join_room", []string{"alt+4"}, "join room", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.FocusJoin }},
+	{"focus_input", []string{"alt+5"}, "chat input", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.FocusInput }},
+	{"focus_view", []string{"alt+6"}, "chat view", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.FocusView }},
+	{"grow_sidebar", []string{"f8"}, "widen sidebar", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.GrowSidebar }},
+	{"shrink_sidebar", []string{"f7"}, "narrow sidebar", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.ShrinkSidebar }},
+	{"toggle_sidebar", []string{"f2"}, "toggle sidebar", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.ToggleSidebar }},
+	{"zen", []string{"f5"}, "zen mode", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.Zen }},
+	{"details", []string{"f3"}, "toggle chat details", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.ToggleDetails }},
+	{"focus_details", []string{"alt+7"}, "chat details", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.FocusDetails }},
+	{"links", []string{"ctrl+l"}, "open or copy a link", KEY_SCOPE_GLOBAL, func(k *keyMap) *key.Binding { return &k.Links }},
+	{"accept_request", []string{"ctrl+a"}, "accept request", KEY_SCOPE_REQUESTS, func(k *keyMap) *key.Binding { return &k.Accept }},
+	{"reject_request", []string{"ctrl+x"}, "reject request", KEY_SCOPE_REQUESTS, func(k *keyMap) *key.Binding { return &k.Reject }},
+	{"filter", []string{"/"}, "filter chats", KEY_SCOPE_CHATS, func(k *keyMap) *key.Binding { return &k.Filter }},
+	{"react", []string{"r"}, "react to message", KEY_SCOPE_VIEW, func(k *keyMap) *key.Binding { return &k.React }},
+	{"reactors", []string{"w"}, "show who reacted", KEY_SCOPE_VIEW, func(k *keyMap) *key.Binding { return &k.Reactors }},
+	{"raw_toggle", []string{"m"}, "toggle raw text", KEY_SCOPE_VIEW, func(k *keyMap) *key.Binding { return &k.RawToggle }},
+	{"download", []string{"d"}, "download attachment", KEY_SCOPE_VIEW, func(k *keyMap) *key.Binding { return &k.Download }},
+	{"export", []string{"e"}, "export chat", KEY_SCOPE_VIEW, func(k *keyMap) *key.Binding { return &k.Export }},
+	{"delete", []string{"x"}, "delete message (admins)", KEY_SCOPE_VIEW, func(k *keyMap) *key.Binding { return &k.Delete }},
+	// Terminals send shift+enter as enter, so it cannot start a new line
+	{"new_line", []string{"alt+enter", "ctrl+j"}, "new line", KEY_SCOPE_INPUT, func(k *keyMap) *key.Binding { return &k.NewLine }},
+	{"editor", []string{"ctrl+o"}, "write message in $EDITOR", KEY_SCOPE_INPUT, func(k *keyMap) *key.Binding { return &k.Editor }},
+	{"complete", []string{"tab"}, "accept suggestion", KEY_SCOPE_COMPLETION, func(k *keyMap) *key.Binding { return &k.Complete }},
+	{"copy_link", []string{"c"}, "copy link", KEY_SCOPE_LINKS, func(k *keyMap) *key.Binding { return &k.CopyLink }},
+	{"search", []string{"/"}, "search messages", KEY_SCOPE_VIEW, func(k *keyMap) *key.Binding { return &k.Search }},
+	{"next_match", []string{"n"}, "next match", KEY_SCOPE_VIEW, func(k *keyMap) *key.Binding { return &k.NextMatch }},
+	{"prev_match", []string{"N"}, "previous match", KEY_SCOPE_VIEW, func(k *keyMap) *key.Binding { return &k.PrevMatch }},
+	{"insert", []string{"i"}, "insert mode", KEY_SCOPE_NORMAL, func(k *keyMap) *key.Binding { return &k.Insert }},
+	{"normal_down", []string{"j"}, "move down", KEY_SCOPE_NORMAL, func(k *keyMap) *key.Binding { return &k.NormalDown }},
+	{"normal_up", []string{"k"}, "move up", KEY_SCOPE_NORMAL, func(k *keyMap) *key.Binding { return &k.NormalUp }},
+	{"top", []string{"g"}, "go to top", KEY_SCOPE_NORMAL, func(k *keyMap) *key.Binding { return &k.Top }},
+	{"bottom", []string{"G"}, "go to bottom", KEY_SCOPE_NORMAL, func(k *keyMap) *key.Binding { return &k.Bottom }},
+}
+
+func findKeyAction(name string) (keyAction, bool) {
+	for _, a := range keyActions {
+		if a.name == name {
+			return a, true
+		}
+	}
+	return keyAction{}, false
+}
+
+// Builds the key map from the default keys, replaced by the keys in the
+// config for the actions it names. The normal mode keys are only enabled in
+// vim mode.
+func newKeyMap(cfg Config) keyMap {
+	var k keyMap
+	for _, a := range keyActions {
+		keys := a.keys
+		if v, ok := cfg.Keys[a.name]; ok {
+			keys = v
+		}
+		b := key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyHelp(keys), a.desc+"  "))
+		b.SetEnabled(len(keys) > 0 && (a.scope != KEY_SCOPE_NORMAL || cfg.InputMode == "vim"))
+		*a.binding(&k) = b
+	}
+	// Top is pressed twice i.e. gg
+	var top []string
+	for _, t := range k.Top.Keys() {
+		top = append(top, t+t)
+	}
+	k.Top.SetHelp(keyHelp(top), k.Top.Help().Desc)
+
+	cycle := append(append([]string{}, k.PrevPanel.Keys()...), k.NextPanel.Keys()...)
+	k.Tab = key.NewBinding(key.WithKeys(cycle...), key.WithHelp(keyHelp(cycle), "cycle panels  "))
+	k.Tab.SetEnabled(len(cycle) > 0)
+	k.SwitchPanel = switchPanelBinding(k)
+	return k
+}
+
+// Describes the panel focus keys in a single binding i.e. alt+[n] when they
+// only differ by the number of the panel
+func switchPanelBinding(k keyMap) key.Binding {
+	focus := []key.Binding{k.FocusChats, k.FocusReqs, k.FocusSendReq, k.FocusJoin, k.FocusInput, k.FocusView, k.FocusDetails}
+
+	var keys, panels []string
+	prefix, numbered := "", true
+	for i, b := range focus {
+		if !b.Enabled() {
+			continue
+		}
+		keys = append(keys, b.Keys()...)
+		n := fmt.Sprint(i + 1)
+		p, ok := strings.CutSuffix(b.Keys()[0], n)
+		if !ok || (prefix != "" && p != prefix) {
+			numbered = false
+		}
+		prefix = p
+		panels = append(panels, b.Help().Key+"|"+strings.TrimSpace(b.Help().Desc))
+	}
+	if len(keys) == 0 {
+		return key.NewBinding(key.WithDisabled())
+	}
+
+	help := ""
+	if numbered {
+		help = keyHelp([]string{prefix}) + "[n]"
+		for i := range panels {
+			panels[i] = strings.TrimPrefix(panels[i], keyHelp([]string{prefix}))
+		}
+	}
+	return key.NewBinding(
+		key.WithKeys(keys...),
+		key.WithHelp(help, "switch panel ("+strings.Join(panels, " ")+")  "),
+	)
+}
+
+var keySymbols = strings.NewReplacer(
+	"shift+tab", "󰌥",
+	"tab", "󰌒",
+	"enter", "󰌑",
+	"up", "↑",
+	"down", "↓",
+	"left", "←",
+	"right", "→",
+)
+
+// Formats keys for the help i.e. alt+enter as alt+󰌑
+func keyHelp(keys []string) string {
+	help := make([]string, len(keys))
+	for i, k := range keys {
+		help[i] = keySymbols.Replace(k)
+	}
+	return strings.Join(help, "/")
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Help, k.Quit, k.Palette, k.SwitchPanel, k.Tab}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down},
+		{k.Reject, k.Accept},
+		{k.Help, k.Quit, k.Palette},
+		{k.Enter, k.SwitchPanel},
+		{k.NewLine, k.Editor},
+		{k.React, k.Reactors, k.RawToggle, k.Download},
+		{k.Links, k.Export, k.Delete, k.Filter},
+		{k.ToggleSidebar, k.ToggleDetails, k.Zen, k.ShrinkSidebar, k.GrowSidebar},
+		{k.Search, k.NextMatch, k.PrevMatch},
+		{k.Insert, k.NormalDown, k.NormalUp, k.Top, k.Bottom},
+	}
+}
+
+// Only the filter keys are handled by the chat list. Moving through it is
+// done by the chat model.
+func (k keyMap) chatListKeyMap() list.KeyMap {
+	var accept []string
+	for _, b := range []key.Binding{k.Enter, k.NextPanel, k.PrevPanel, k.Up, k.Down} {
+		accept = append(accept, b.Keys()...)
+	}
+	return list.KeyMap{
+		Filter:               k.Filter,
+		ClearFilter:          k.Cancel,
+		CancelWhileFiltering: k.Cancel,
+		AcceptWhileFiltering: key.NewBinding(key.WithKeys(accept...)),
+	}
+}
+
+// Checks if two scopes can be active at the same time
+func keyScopesOverlap(a, b int) bool {
+	if a == b {
+		return true
+	}
+	if a == KEY_SCOPE_NORMAL || b == KEY_SCOPE_NORMAL {
+		// Normal mode is left to type into the inputs and pickers
+		other := a + b - KEY_SCOPE_NORMAL
+		return other != KEY_SCOPE_INPUT && other != KEY_SCOPE_LINKS && other != KEY_SCOPE_COMPLETION
+	}
+	if a == KEY_SCOPE_COMPLETION || b == KEY_SCOPE_COMPLETION {
+		// Suggestions are moved through with the move keys
+		return a == KEY_SCOPE_MOVE || b == KEY_SCOPE_MOVE
+	}
+	return a == KEY_SCOPE_GLOBAL || a == KEY_SCOPE_MOVE || b == KEY_SCOPE_GLOBAL || b == KEY_SCOPE_MOVE
+}
+
+// Checks the custom keys name known actions and that no key is bound to two
+// actions that can be active at the same time
+func checkKeys(custom map[string][]string) error {
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := findKeyAction(name); !ok {
+			return fmt.Errorf("unknown action %q", name)
+		}
+		if name == "quit" && len(custom[name]) == 0 {
+			return fmt.Errorf("quit must have a key")
+		}
+	}
+
+	bound := map[string][]keyAction{}
+	for _, a := range keyActions {
+		keys := a.keys
+		if v, ok := custom[a.name]; ok {
+			keys = v
+		}
+		for _, k := range keys {
+			for _, other := range bound[k] {
+				if keyScopesOverlap(a.scope, other.scope) {
+					return fmt.Errorf("%s is bound to both %s and %s", k, other.name, a.name)
+				}
+			}
+			bound[k] = append(bound[k], a)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCheckKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		custom  map[string][]string
+		wantErr bool
+	}{
+		{"defaults", nil, false},
+		{"rebound", map[string][]string{"help": {"f9"}}, false},
+		{"unbound", map[string][]string{"react": {}}, false},
+		{"unknown action", map[string][]string{"fly": {"f9"}}, true},
+		{"quit unbound", map[string][]string{"quit": {}}, true},
+		{"clash with a global key", map[string][]string{"react": {"ctrl+c"}}, true},
+		{"clash in one scope", map[string][]string{"react": {"w"}}, true},
+		{"same key in other scopes", map[string][]string{"react": {"ctrl+j"}}, false},
+		{"normal mode key typed in the input", map[string][]string{"insert": {"ctrl+o"}}, false},
+		{"normal mode key in the view", map[string][]string{"insert": {"r"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkKeys(tt.custom); (err != nil) != tt.wantErr {
+				t.Errorf("checkKeys(%v) = %v, want error %v", tt.custom, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoginKeysAreConfigurable(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Keys = map[string][]string{"quit": {"ctrl+q"}, "cancel": {"f10"}}
+
+	tests := []struct {
+		key  tea.KeyMsg
+		quit bool
+	}{
+		{tea.KeyMsg{Type: tea.KeyCtrlQ}, true},
+		{tea.KeyMsg{Type: tea.KeyF10}, true},
+		{tea.KeyMsg{Type: tea.KeyCtrlC}, false},
+		{tea.KeyMsg{Type: tea.KeyEsc}, false},
+	}
+	for _, tt := range tests {
+		for name, m := range map[string]tea.Model{
+			"login":  NewLoginModel("me", nil, cfg),
+			"create": NewCreateModel(nil, cfg),
+		} {
+			_, cmd := m.Update(tt.key)
+			quit := false
+			if cmd != nil {
+				_, quit = cmd().(tea.QuitMsg)
+			}
+			if quit != tt.quit {
+				t.Errorf("%s: %s quits = %v, want %v", name, tt.key, quit, tt.quit)
+			}
+		}
+	}
+}
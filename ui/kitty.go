@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+)
+
+// Character kitty replaces with the cells of an image placed with U=1
+const kittyPlaceholder = '\U0010EEEE'
+
+// Combining characters that mark the row of a placeholder cell, from kitty's
+// rowcolumn-diacritics.txt. Cells after the first one of a row inherit its row
+// and the next column, so only rows need to be marked.
+var kittyRowDiacritics = []rune{
+	0x0305, 0x030D, 0x030E, 0x0310, 0x0312, 0x033D, 0x033E, 0x033F,
+	0x0346, 0x034A, 0x034B, 0x034C, 0x0350, 0x0351, 0x0352, 0x0357,
+	0x035B, 0x0363, 0x0364, 0x0365, 0x0366, 0x0367, 0x0368, 0x0369,
+	0x036A, 0x036B, 0x036C, 0x036D, 0x036E, 0x036F, 0x0483, 0x0484,
+}
+
+// Escape sequences uploading img to the terminal under id. Data is sent as
+// png in chunks of 4096 bytes as required by the protocol.
+func kittyTransmit(id uint32, img image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var b strings.Builder
+	for i := 0; i < len(data); i += 4096 {
+		end := min(i+4096, len(data))
+		more := 1
+		if end == len(data) {
+			more = 0
+		}
+		if i == 0 {
+			fmt.Fprintf(&b, "\x1b_Ga=t,f=100,q=2,i=%d,m=%d;%s\x1b\\", id, more, data[i:end])
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, data[i:end])
+		}
+	}
+	return b.String(), nil
+}
+
+// Escape sequences replacing the placement of image id with a virtual
+// placement of the given size, displayed wherever its placeholders are
+func kittyPlace(id uint32, cols, rows int) string {
+	return fmt.Sprintf("\x1b_Ga=d,d=i,i=%d,q=2\x1b\\\x1b_Ga=p,U=1,i=%d,c=%d,r=%d,q=2\x1b\\", id, id, cols, rows)
+}
+
+// Placeholder cells for a virtual placement of image id. The image id is
+// encoded in the foreground colour.
+func kittyPlaceholders(id uint32, cols, rows int) string {
+	color := fmt.Sprintf("\x1b[38;2;%d;%d;%dm", id>>16&0xff, id>>8&0xff, id&0xff)
+
+	lines := make([]string, rows)
+	for row := range lines {
+		lines[row] = color +
+			string([]rune{kittyPlaceholder, kittyRowDiacritics[row], kittyRowDiacritics[0]}) +
+			strings.Repeat(string(kittyPlaceholder), cols-1) +
+			"\x1b[39m"
+	}
+	return strings.Join(lines, "\n")
+}
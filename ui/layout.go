@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Longest gap between messages of a sender that are grouped together
+const groupWindow = 5 * time.Minute
+
+// Formats when a message was sent as configured. Dates are left to the day
+// separators.
+func (m chatModel) formatTimestamp(sentAt time.Time) string {
+	switch m.cfg.Timestamps {
+	case "off":
+		return ""
+	case "relative":
+		return formatAgo(sentAt)
+	}
+	if m.cfg.Clock == "12h" {
+		return sentAt.Local().Format("3:04 PM")
+	}
+	return sentAt.Local().Format("15:04")
+}
+
+// Formats how long ago t was, e.g. 5m ago
+func formatAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Local().Date()
+	by, bm, bd := b.Local().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// Checks if msg is shown under the sender of prev instead of repeating it
+func continuesGroup(prev, msg *pb.Message) bool {
+	if prev == nil || prev.Type == pb.Message_MESSAGE_TYPE_NOTIFICATION || msg.Type == pb.Message_MESSAGE_TYPE_NOTIFICATION {
+		return false
+	}
+	sent, prevSent := msg.SentAt.AsTime(), prev.SentAt.AsTime()
+	return prev.Sender.GetUsername() == msg.Sender.GetUsername() &&
+		sameDay(prevSent, sent) &&
+		sent.Sub(prevSent) < groupWindow
+}
+
+// Renders the line separating the messages of different days, or nothing if
+// msg was sent the same day as prev or its day is not known
+func (m chatModel) formatDaySeparator(prev, msg *pb.Message) string {
+	if msg.SentAt == nil {
+		return ""
+	}
+	sent := msg.SentAt.AsTime()
+	if prev != nil && sameDay(prev.SentAt.AsTime(), sent) {
+		return ""
+	}
+
+	label := sent.Local().Format("Monday, 2 January 2006")
+	switch now := time.Now(); {
+	case sameDay(sent, now):
+		label = "Today"
+	case sameDay(sent, now.AddDate(0, 0, -1)):
+		label = "Yesterday"
+	}
+	label = " " + label + " "
+	rule := strings.Repeat("─", max(0, (m.viewport.Width-1-lipgloss.Width(label))/2))
+	return timestampTextStyle.Render(rule + label + rule)
+}
+
+// Schedules rendering the messages again once the relative timestamps, or
+// the days the separators name, are out of date
+func (m chatModel) clockTick() tea.Cmd {
+	now := time.Now()
+	return tea.Tick(m.nextClockTick(now).Sub(now), func(time.Time) tea.Msg {
+		return statusMsg{sType: STATUS_CLOCK_TICK}
+	})
+}
+
+// Relative timestamps change every minute, the rest at midnight
+func (m chatModel) nextClockTick(now time.Time) time.Time {
+	if m.cfg.Timestamps == "relative" {
+		return now.Truncate(time.Minute).Add(time.Minute)
+	}
+	y, mo, d := now.Date()
+	return time.Date(y, mo, d+1, 0, 0, 0, 0, now.Location())
+}
+
+// Picks a colour for username from the theme, the same every time
+func userStyle(username string) lipgloss.Style {
+	h := fnv.New32a()
+	h.Write([]byte(username))
+	return defaultStyle.Copy().Foreground(userColors[h.Sum32()%uint32(len(userColors))])
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+)
+
+func TestContinuesGroup(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	notification := testMessage("n", "alice", at)
+	notification.Type = pb.Message_MESSAGE_TYPE_NOTIFICATION
+
+	tests := []struct {
+		name string
+		prev *pb.Message
+		msg  *pb.Message
+		want bool
+	}{
+		{"first message", nil, testMessage("1", "alice", at), false},
+		{"same sender soon after", testMessage("1", "alice", at), testMessage("2", "alice", at.Add(time.Minute)), true},
+		{"other sender", testMessage("1", "alice", at), testMessage("2", "bob", at.Add(time.Minute)), false},
+		{"after the window", testMessage("1", "alice", at), testMessage("2", "alice", at.Add(groupWindow)), false},
+		{"next day", testMessage("1", "alice", at.Add(11*time.Hour+59*time.Minute)), testMessage("2", "alice", at.Add(12*time.Hour+time.Minute)), false},
+		{"after a notification", notification, testMessage("2", "alice", at), false},
+		{"notification", testMessage("1", "alice", at), notification, false},
+	}
+	for _, tt := range tests {
+		if got := continuesGroup(tt.prev, tt.msg); got != tt.want {
+			t.Errorf("%s: continuesGroup = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDaySeparator(t *testing.T) {
+	m := newTestChatModel()
+	now := time.Now()
+	noTime := testMessage("2", "alice", now)
+	noTime.SentAt = nil
+
+	tests := []struct {
+		name string
+		prev *pb.Message
+		msg  *pb.Message
+		want string // in the separator, or nothing for none
+	}{
+		{"today", nil, testMessage("1", "alice", now), "Today"},
+		{"yesterday", nil, testMessage("1", "alice", now.AddDate(0, 0, -1)), "Yesterday"},
+		{"older", nil, testMessage("1", "alice", time.Date(2020, 3, 2, 12, 0, 0, 0, time.Local)), "Monday, 2 March 2020"},
+		{"same day", testMessage("1", "alice", now), testMessage("2", "alice", now), ""},
+		{"unknown time", testMessage("1", "alice", now.AddDate(0, 0, -3)), noTime, ""},
+	}
+	for _, tt := range tests {
+		got := ansiPattern.ReplaceAllString(m.formatDaySeparator(tt.prev, tt.msg), "")
+		if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+			t.Errorf("%s: separator %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClockTickRendersAgain(t *testing.T) {
+	m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.cfg.Timestamps = "relative"
+	m.activeChat = "a"
+	i, chat, _ := m.activeChatItem()
+	chat.messages = []*pb.Message{testMessage("1", "alice", time.Now().Add(-5*time.Minute))}
+	m.chatList.SetItem(i, chat)
+	m.renderMessages()
+
+	// Time passes without anything else happening
+	chat.messages[0].SentAt.Seconds -= 60 * 60
+	model, cmd := m.Update(statusMsg{sType: STATUS_CLOCK_TICK})
+	m = model.(chatModel)
+	if cmd == nil {
+		t.Error("the clock stopped ticking")
+	}
+	if view := ansiPattern.ReplaceAllString(m.viewport.View(), ""); !strings.Contains(view, "1h ago") {
+		t.Errorf("relative time was not updated:\n%s", view)
+	}
+}
+
+func TestNextClockTick(t *testing.T) {
+	now := time.Date(2024, 5, 1, 23, 58, 30, 0, time.Local)
+	tests := []struct {
+		timestamps string
+		want       time.Time
+	}{
+		{"relative", time.Date(2024, 5, 1, 23, 59, 0, 0, time.Local)},
+		{"absolute", time.Date(2024, 5, 2, 0, 0, 0, 0, time.Local)},
+		{"off", time.Date(2024, 5, 2, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		m := newTestChatModel()
+		m.cfg.Timestamps = tt.timestamps
+		if got := m.nextClockTick(now); !got.Equal(tt.want) {
+			t.Errorf("%s: next tick at %v, want %v", tt.timestamps, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package ui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+var urlPattern = regexp.MustCompile("\\b(?:https?|ftp)://[^\\s\\x00-\\x1f\\x7f<>\"'`()\\[\\]]+")
+
+type chatLink struct {
+	text   string
+	url    string
+	sender string
+}
+
+// A link found in text, either a url or a token matched by a linkifier rule
+type linkMatch struct {
+	start int
+	end   int
+	text  string
+	url   string
+}
+
+// A link shown in the viewport. Links are drawn as OSC 8 hyperlinks over the
+// rendered text as lipgloss does not know how to measure them.
+type linkPlacement struct {
+	url  string
+	line int
+	col  int
+	text string // the rendered cells the link covers
+}
+
+// Finds the urls in text, without trailing punctuation, and the tokens
+// matched by the linkifier rules. Tokens inside urls are not matched.
+func matchLinks(text string, rules []LinkRule) []linkMatch {
+	var matches []linkMatch
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		url := strings.TrimRight(text[loc[0]:loc[1]], ".,;:!?")
+		matches = append(matches, linkMatch{start: loc[0], end: loc[0] + len(url), text: url, url: url})
+	}
+
+	overlaps := func(start, end int) bool {
+		for _, m := range matches {
+			if start < m.end && end > m.start {
+				return true
+			}
+		}
+		return false
+	}
+	for _, r := range rules {
+		for _, loc := range r.re.FindAllStringSubmatchIndex(text, -1) {
+			if loc[0] == loc[1] || overlaps(loc[0], loc[1]) {
+				continue
+			}
+			url := string(r.re.ExpandString(nil, r.URL, text, loc))
+			if !safeURL(url) {
+				continue
+			}
+			matches = append(matches, linkMatch{
+				start: loc[0],
+				end:   loc[1],
+				text:  text[loc[0]:loc[1]],
+				url:   url,
+			})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+	return matches
+}
+
+// Returns every link sent in msgs, most recent first
+func chatLinks(msgs []*pb.Message, rules []LinkRule) []chatLink {
+	var links []chatLink
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Type != pb.Message_MESSAGE_TYPE_REGULAR {
+			continue
+		}
+		for _, l := range matchLinks(msgs[i].Content, rules) {
+			links = append(links, chatLink{text: l.text, url: l.url, sender: msgs[i].Sender.GetUsername()})
+		}
+	}
+	return links
+}
+
+// Finds the links in the rendered content of a message. Long urls wrapped over
+// several lines are matched to the full url in links.
+func placeLinks(content string, links []chatLink, rules []LinkRule) []linkPlacement {
+	var placements []linkPlacement
+	for i, line := range strings.Split(content, "\n") {
+		plain := ansiPattern.ReplaceAllString(line, "")
+		for _, match := range matchLinks(plain, rules) {
+			if match.url == match.text {
+				for _, l := range links {
+					if strings.HasPrefix(l.url, match.text) {
+						match.url = l.url
+						break
+					}
+				}
+			}
+			col := lipgloss.Width(plain[:match.start])
+			placements = append(placements, linkPlacement{
+				url:  match.url,
+				line: i,
+				col:  col,
+				text: cutCells(line, col, col+lipgloss.Width(match.text)),
+			})
+		}
+	}
+	return placements
+}
+
+// Rewrites the tokens matched by the linkifier rules in content as markdown
+// links. Urls are left as they are since markdown already links them.
+func markdownLinks(content string, rules []LinkRule) string {
+	var b strings.Builder
+	last := 0
+	for _, l := range matchLinks(content, rules) {
+		if l.url == l.text {
+			continue
+		}
+		fmt.Fprintf(&b, "%s[%s](%s)", content[last:l.start], l.text, l.url)
+		last = l.end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// Previews where the tokens matched by the linkifier rules in a message lead
+// i.e. PROJ-1234 → https://jira.example.com/browse/PROJ-1234
+func (m chatModel) formatLinkPreviews(msg *pb.Message) string {
+	if msg.Type != pb.Message_MESSAGE_TYPE_REGULAR {
+		return ""
+	}
+
+	var lines []string
+	for _, l := range matchLinks(msg.Content, m.cfg.Linkifiers) {
+		if l.url != l.text {
+			lines = append(lines, reactionTextStyle.Render(l.text+" → "+l.url))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Returns the cells of a rendered line from start up to end, keeping the
+// styles active at start
+func cutCells(line string, start, end int) string {
+	var styles, cells strings.Builder
+	col := 0
+	for i := 0; i < len(line) && col < end; {
+		if line[i] == '\x1b' {
+			if loc := ansiPattern.FindStringIndex(line[i:]); loc != nil && loc[0] == 0 {
+				if col < start {
+					styles.WriteString(line[i : i+loc[1]])
+				} else {
+					cells.WriteString(line[i : i+loc[1]])
+				}
+				i += loc[1]
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if col >= start {
+			cells.WriteRune(r)
+		}
+		col += runewidth.RuneWidth(r)
+		i += size
+	}
+	return styles.String() + cells.String() + "\x1b[0m"
+}
+
+// Wraps text in an OSC 8 hyperlink to url
+func hyperlink(url, text string) string {
+	return fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", url, text)
+}
+
+// Returns the links fully inside the viewport as hyperlinks and the screen
+// rows they are on. x and y are the screen position of the viewport.
+func linkOverlay(placements []linkPlacement, yOffset, height, x, y int) (string, []int) {
+	var b strings.Builder
+	var rows []int
+	for _, pl := range placements {
+		if pl.line < yOffset || pl.line >= yOffset+height {
+			continue
+		}
+		fmt.Fprintf(&b, "\x1b7\x1b[%d;%dH%s\x1b8", y+pl.line-yOffset+1, x+pl.col+1, hyperlink(pl.url, pl.text))
+		rows = append(rows, y+pl.line-yOffset)
+	}
+	return b.String(), rows
+}
+
+// Copies s to the system clipboard with OSC 52, which also works over ssh
+func copyToClipboard(s string) tea.Cmd {
+	return writeTerminal(fmt.Sprintf("\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(s))))
+}
+
+// Checks that a link sent by another user is a web link. Control characters
+// would end the hyperlink drawn around it and other schemes may run programs.
+func safeURL(link string) bool {
+	if strings.IndexFunc(link, unicode.IsControl) >= 0 {
+		return false
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	switch u.Scheme {
+	case "http", "https", "ftp":
+		return true
+	}
+	return false
+}
+
+// Opens url in the default browser
+func openURL(url string) tea.Cmd {
+	return func() tea.Msg {
+		if !safeURL(url) {
+			return errMsg{fmt.Errorf("Cannot open %q", url)}
+		}
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = exec.Command("open", url)
+		case "windows":
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+		default:
+			cmd = exec.Command("xdg-open", url)
+		}
+		if err := cmd.Start(); err != nil {
+			return errMsg{err}
+		}
+		go cmd.Wait()
+		return nil
+	}
+}
+
+func (m *chatModel) openLinkPicker() {
+	m.linkPicker = true
+	m.linkPickerIdx = 0
+	m.pickerLinks = chatLinks(m.activeMessages(), m.cfg.Linkifiers)
+}
+
+// Renders the link picker to fill the viewport
+func (m chatModel) formatLinkPicker(width, height int) string {
+	lines := []string{senderTextStyle.Render("Links") + " " + suggestionDescStyle.Render(fmt.Sprintf("%s open  %s copy  %s close", m.keys.Enter.Help().Key, m.keys.CopyLink.Help().Key, m.keys.Cancel.Help().Key))}
+	if len(m.pickerLinks) == 0 {
+		lines = append(lines, suggestionStyle.Render("No links in this chat"))
+	}
+
+	// Scroll so the selected link is always shown
+	rows := max(1, height-1)
+	start := max(0, m.linkPickerIdx-rows+1)
+	end := min(len(m.pickerLinks), start+rows)
+	for i := start; i < end; i++ {
+		l := m.pickerLinks[i]
+		desc := " " + suggestionDescStyle.Render(l.sender)
+		label := l.url
+		if l.text != l.url {
+			label = l.text + " → " + l.url
+		}
+		line := runewidth.Truncate(label, max(1, width-lipgloss.Width(desc)-2), "…") + desc
+		if i == m.linkPickerIdx {
+			lines = append(lines, selectedSuggestionStyle.Render("> ")+line)
+			continue
+		}
+		lines = append(lines, suggestionStyle.Render("  ")+line)
+	}
+	return defaultStyle.Copy().Width(width).Height(height).Render(strings.Join(lines, "\n"))
+}
+
+func (m chatModel) updateLinkPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.linkPickerIdx > 0 {
+			m.linkPickerIdx--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.linkPickerIdx < len(m.pickerLinks)-1 {
+			m.linkPickerIdx++
+		}
+	case key.Matches(msg, m.keys.Enter):
+		if m.linkPickerIdx < len(m.pickerLinks) {
+			m.linkPicker = false
+			cmd = openURL(m.pickerLinks[m.linkPickerIdx].url)
+		}
+	case key.Matches(msg, m.keys.CopyLink):
+		if m.linkPickerIdx < len(m.pickerLinks) {
+			m.linkPicker = false
+			cmd = copyToClipboard(m.pickerLinks[m.linkPickerIdx].url)
+			m.msg = successTextStyle.Render("Copied " + m.pickerLinks[m.linkPickerIdx].url)
+		}
+	case key.Matches(msg, m.keys.Cancel, m.keys.Links):
+		m.linkPicker = false
+	}
+	if !m.linkPicker {
+		// Redraw the hyperlinks hidden by the picker
+		cmd = tea.Batch(cmd, m.drawOverlays())
+	}
+
+	return m, cmd
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+)
+
+func TestMatchLinks(t *testing.T) {
+	rules := []LinkRule{
+		{URL: "https://jira.example.com/browse/$0", re: regexp.MustCompile(`\bPROJ-\d+\b`)},
+		{URL: "$1", re: regexp.MustCompile(`open (\S+)`)},
+		{URL: "https://example.com/$1", re: regexp.MustCompile(`tag:(\S+)`)},
+	}
+	tests := []struct {
+		name string
+		text string
+		want []string // the urls matched
+	}{
+		{"no links", "hello there", nil},
+		{"url", "see https://example.com/a?b=c", []string{"https://example.com/a?b=c"}},
+		{"trailing punctuation", "go to http://example.com.", []string{"http://example.com"}},
+		{"parenthesised", "(https://example.com)", []string{"https://example.com"}},
+		{"linkifier", "fixed in PROJ-12", []string{"https://jira.example.com/browse/PROJ-12"}},
+		{"linkifier inside a url", "https://example.com/PROJ-12", []string{"https://example.com/PROJ-12"}},
+		{"ordered", "PROJ-1 and https://a.com", []string{"https://jira.example.com/browse/PROJ-1", "https://a.com"}},
+		{"escape ends a url", "https://a.com\x1b]8;;https://b.com\x07", []string{"https://a.com", "https://b.com"}},
+		{"bell ends a url", "https://a.com\x07x", []string{"https://a.com"}},
+		{"delete ends a url", "https://a.com\x7fx", []string{"https://a.com"}},
+		{"unsafe linkifier scheme", "open file:///etc/passwd", nil},
+		{"linkifier with a control character", "tag:a\x1b]8;;x", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range matchLinks(tt.text, rules) {
+				got = append(got, m.url)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchLinks(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com", true},
+		{"http://example.com/a b", true},
+		{"ftp://example.com/file", true},
+		{"file:///etc/passwd", false},
+		{"javascript:alert(1)", false},
+		{"-a https://example.com", false},
+		{"https://", false},
+		{"https://example.com\x1b]8;;\x1b\\", false},
+		{"https://example.com\x07", false},
+	}
+	for _, tt := range tests {
+		if got := safeURL(tt.url); got != tt.want {
+			t.Errorf("safeURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestLinksOnlyInMessageContent(t *testing.T) {
+	m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.activeChat = "a"
+	m.cfg.Linkifiers = []LinkRule{{URL: "https://jira.example.com/browse/$0", re: regexp.MustCompile(`\bPROJ-\d+\b`)}}
+
+	i, chat, _ := m.activeChatItem()
+	chat.messages = []*pb.Message{
+		testMessage("hello", "PROJ-1", time.Now()),
+		testMessage("see PROJ-2", "PROJ-1", time.Now()),
+	}
+	m.chatList.SetItem(i, chat)
+	m.renderMessages()
+
+	if len(m.links) != 1 || m.links[0].url != "https://jira.example.com/browse/PROJ-2" {
+		t.Fatalf("links = %+v, want only PROJ-2 from the content", m.links)
+	}
+	line := strings.Split(m.viewport.View(), "\n")[m.links[0].line]
+	plain := ansiPattern.ReplaceAllString(line, "")
+	if col := strings.Index(plain, "PROJ-2"); col != m.links[0].col {
+		t.Errorf("link placed at column %d, want %d in %q", m.links[0].col, col, plain)
+	}
+}
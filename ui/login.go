@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/Ayobami0/cli-chat/pb"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -22,6 +23,8 @@ type loginModel struct {
 	validationError bool
 	client          pb.ChatServiceClient
 	authRes         *pb.UserAuthenticatedResponse
+	cfg             Config
+	keys            keyMap
 }
 
 func (m loginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -52,10 +55,10 @@ func (m loginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	case tea.KeyMsg:
 		m.password.Placeholder = "Password"
-		switch msg.String() {
-		case "ctrl+c", "esc":
+		switch {
+		case key.Matches(msg, m.keys.Quit, m.keys.Cancel):
 			return m, tea.Quit
-		case "enter":
+		case key.Matches(msg, m.keys.Enter):
 			if !m.isLoggedIn {
 				var password string
 				password = m.password.Value()
@@ -81,11 +84,11 @@ func (m loginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				),
 				)
 			} else if m.isLoggedIn {
-				return enterChat(m.client, m.width, m.height, m.authRes)
+				return enterChat(m.client, m.width, m.height, m.authRes, m.cfg)
 			}
 		default:
 			if m.isLoggedIn {
-				return enterChat(m.client, m.width, m.height, m.authRes)
+				return enterChat(m.client, m.width, m.height, m.authRes, m.cfg)
 			}
 		}
 	}
@@ -112,7 +115,9 @@ func (m loginModel) View() string {
 func (m loginModel) Init() tea.Cmd {
 	return nil
 }
-func NewLoginModel(username string, client pb.ChatServiceClient) loginModel {
+func NewLoginModel(username string, client pb.ChatServiceClient, cfg Config) loginModel {
+	applyTheme(cfg.theme)
+
 	// Spinner
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
@@ -130,6 +135,8 @@ func NewLoginModel(username string, client pb.ChatServiceClient) loginModel {
 		password: passwordInput,
 		spinner:  sp,
 		client:   client,
+		cfg:      cfg,
+		keys:     newKeyMap(cfg),
 	}
 
 	return model
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"container/list"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+var trailingPaddingPattern = regexp.MustCompile(`(?:(?:\x1b\[[0-9;]*m)| )+$`)
+
+// Most rendered messages kept in the cache
+const markdownCacheSize = 1000
+
+type markdownKey struct {
+	content string
+	width   int
+}
+
+type markdownEntry struct {
+	key markdownKey
+	out string
+}
+
+// Renders message content as markdown. Rendered content is cached as glamour
+// is too slow to re-render every message on each update; the least recently
+// used is dropped once the cache is full.
+type markdownRenderer struct {
+	width    int
+	renderer *glamour.TermRenderer
+	cache    map[markdownKey]*list.Element
+	order    *list.List // most recently used first
+}
+
+func newMarkdownRenderer() *markdownRenderer {
+	return &markdownRenderer{cache: map[markdownKey]*list.Element{}, order: list.New()}
+}
+
+func (r *markdownRenderer) render(content string, width int) string {
+	if width < 1 {
+		return content
+	}
+	key := markdownKey{content, width}
+	if e, ok := r.cache[key]; ok {
+		r.order.MoveToFront(e)
+		return e.Value.(markdownEntry).out
+	}
+	if r.renderer == nil || width != r.width {
+		renderer, err := glamour.NewTermRenderer(
+			glamour.WithStyles(markdownStyle()),
+			glamour.WithColorProfile(lipgloss.ColorProfile()),
+			glamour.WithWordWrap(width),
+			glamour.WithPreservedNewLines(),
+		)
+		if err != nil {
+			return content
+		}
+		r.renderer, r.width = renderer, width
+	}
+
+	out, err := r.renderer.Render(content)
+	if err != nil {
+		return content
+	}
+	out = trimRendered(out)
+	r.cache[key] = r.order.PushFront(markdownEntry{key, out})
+	if r.order.Len() > markdownCacheSize {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.cache, oldest.Value.(markdownEntry).key)
+	}
+
+	return out
+}
+
+// The glamour style of the theme without the document margins, which would
+// waste space around every message
+func markdownStyle() ansi.StyleConfig {
+	style := glamour.DarkStyleConfig
+	if markdownTheme == "light" {
+		style = glamour.LightStyleConfig
+	}
+	margin := uint(0)
+	style.Document.Margin = &margin
+	style.Document.BlockPrefix = ""
+	style.Document.BlockSuffix = ""
+	return style
+}
+
+// Removes the blank lines around rendered markdown and the padding glamour
+// adds to the end of each line
+func trimRendered(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = trailingPaddingPattern.ReplaceAllString(l, "\x1b[0m")
+	}
+
+	isBlank := func(l string) bool { return strings.TrimSpace(ansiPattern.ReplaceAllString(l, "")) == "" }
+	for len(lines) > 0 && isBlank(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && isBlank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMarkdownCacheIsBounded(t *testing.T) {
+	r := newMarkdownRenderer()
+	for i := 0; i < markdownCacheSize+10; i++ {
+		r.render(fmt.Sprintf("message %d", i), 40)
+	}
+	if len(r.cache) != markdownCacheSize || r.order.Len() != markdownCacheSize {
+		t.Fatalf("cache holds %d messages, want %d", len(r.cache), markdownCacheSize)
+	}
+	if _, ok := r.cache[markdownKey{"message 0", 40}]; ok {
+		t.Error("the least recently used message is still cached")
+	}
+
+	// Using a message keeps it cached
+	r.render("message 10", 40)
+	r.render("another", 40)
+	if _, ok := r.cache[markdownKey{"message 10", 40}]; !ok {
+		t.Error("a recently used message was dropped")
+	}
+}
+
+func TestMarkdownCacheIsKeyedByWidth(t *testing.T) {
+	r := newMarkdownRenderer()
+	r.render("hello", 40)
+	r.render("hello", 20)
+	if len(r.cache) != 2 {
+		t.Errorf("cache holds %d renders, want 2", len(r.cache))
+	}
+}
+
+func TestTrimRendered(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"\n  \nhello   \n\n", "hello\x1b[0m"},
+		{"a\x1b[0m  \nb", "a\x1b[0m\nb"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := trimRendered(tt.in); got != tt.want {
+			t.Errorf("trimRendered(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
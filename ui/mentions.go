@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	"github.com/sahilm/fuzzy"
+)
+
+var (
+	mentionPatternsMu sync.Mutex
+	mentionPatterns   = map[string]*regexp.Regexp{} // by username
+)
+
+// Returns the pattern matching mentions of username. Each is compiled once as
+// it is used for every message on each render.
+func mentionPattern(username string) *regexp.Regexp {
+	mentionPatternsMu.Lock()
+	defer mentionPatternsMu.Unlock()
+
+	if p, ok := mentionPatterns[username]; ok {
+		return p
+	}
+	// Mentions may directly follow the escape codes of rendered markdown
+	p := regexp.MustCompile(`(?i)(^|[^\w@]|\x1b\[[0-9;]*m)(@` + regexp.QuoteMeta(username) + `)\b`)
+	mentionPatterns[username] = p
+	return p
+}
+
+// Checks if content mentions the user with the given username
+func mentionsUser(content, username string) bool {
+	return mentionPattern(username).MatchString(content)
+}
+
+// Styles every mention of username in rendered content. The style of the text
+// around a mention is set again after it, as the mention style ends with a
+// reset.
+func highlightMentions(content, username string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range mentionPattern(username).FindAllStringSubmatchIndex(content, -1) {
+		start, end := loc[4], loc[5]
+		b.WriteString(content[last:start])
+		b.WriteString(mentionTextStyle.Render(content[start:end]))
+		b.WriteString(activeStyle(content[:start]))
+		last = end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// Returns the escape codes of the style in effect at the end of s, the ones
+// since its last reset
+func activeStyle(s string) string {
+	codes := ansiPattern.FindAllString(s, -1)
+	start := 0
+	for i, c := range codes {
+		if c == "\x1b[0m" || c == "\x1b[m" {
+			start = i + 1
+		}
+	}
+	return strings.Join(codes[start:], "")
+}
+
+// Suggests members of the active chat for the @mention being typed
+func (m chatModel) mentionSuggestions(word string) []suggestion {
+	_, chat, ok := m.activeChatItem()
+	if !ok {
+		return nil
+	}
+
+	var usernames []string
+	for _, u := range chat.members {
+		if u.Username != m.user.Username {
+			usernames = append(usernames, u.Username)
+		}
+	}
+	sort.Strings(usernames)
+
+	query := strings.TrimPrefix(word, "@")
+	var items []suggestion
+	if query == "" {
+		for _, u := range usernames {
+			items = append(items, suggestion{value: "@" + u + " "})
+		}
+		return items
+	}
+	for _, match := range fuzzy.Find(query, usernames) {
+		items = append(items, suggestion{value: "@" + match.Str + " "})
+	}
+	return items
+}
+
+// Counts the messages of a chat that arrived since it was last seen, and how
+// many of those mention the current user. Chats are considered seen the first
+// time they are loaded and whenever they are open.
+func (m chatModel) countUnread(c chatItem) (unread, mentions int) {
+	seen, ok := m.seen[c.id]
+	if !ok || c.id == m.activeChat || seen > len(c.messages) {
+		m.seen[c.id] = len(c.messages)
+		return 0, 0
+	}
+
+	for _, msg := range c.messages[seen:] {
+		if msg.Type == pb.Message_MESSAGE_TYPE_NOTIFICATION || msg.Sender.GetUsername() == m.user.Username {
+			continue
+		}
+		unread++
+		if mentionsUser(msg.Content, m.user.Username) {
+			mentions++
+		}
+	}
+	return unread, mentions
+}
@@ -0,0 +1,46 @@
+package ui
+
+import "testing"
+
+func TestMentionsUser(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{"hi @me", true},
+		{"@ME, look", true},
+		{"\x1b[1m@me\x1b[0m", true},
+		{"hi @meow", false},
+		{"mail me@me.com", false},
+		{"@@me", false},
+		{"hi me", false},
+	}
+	for _, tt := range tests {
+		if got := mentionsUser(tt.content, "me"); got != tt.want {
+			t.Errorf("mentionsUser(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestMentionPatternIsCompiledOnce(t *testing.T) {
+	if mentionPattern("me") != mentionPattern("me") {
+		t.Error("the pattern of a username is compiled again")
+	}
+}
+
+func TestHighlightMentionsRestoresStyle(t *testing.T) {
+	// Colours are not rendered in tests, so only the restored style shows
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"hi @me there", "hi @me there"},
+		{"\x1b[1mhi @me there\x1b[0m", "\x1b[1mhi @me\x1b[1m there\x1b[0m"},
+		{"\x1b[1mbold\x1b[0m \x1b[3m@me it\x1b[0m", "\x1b[1mbold\x1b[0m \x1b[3m@me\x1b[3m it\x1b[0m"},
+	}
+	for _, tt := range tests {
+		if got := highlightMentions(tt.content, "me"); got != tt.want {
+			t.Errorf("highlightMentions(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Lines scrolled by a turn of the mouse wheel
+const wheelLines = 3
+
+// Focuses the clicked panel, selects the clicked chat, request or member, presses the
+// clicked button and scrolls the panel under the wheel
+func (m *chatModel) updateMouse(msg tea.MouseMsg) tea.Cmd {
+	if msg.Action != tea.MouseActionPress || m.palette || m.linkPicker || m.searching {
+		return nil
+	}
+	panel, x, y, ok := m.panelAt(msg.X, msg.Y)
+	if !ok || !m.panelVisible(panel) {
+		return nil
+	}
+
+	if msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown {
+		up := msg.Button == tea.MouseButtonWheelUp
+		switch panel {
+		case MESSSAGE_VIEW_PANEL:
+			if up {
+				m.viewport.LineUp(wheelLines)
+			} else {
+				m.viewport.LineDown(wheelLines)
+			}
+			return m.drawOverlays()
+		case CHATS_PANEL, ACTIVE_REQUEST_PANEL, DETAILS_PANEL:
+			// All are lists, moved through by the chat model
+			focused := m.focusedPanel
+			m.focusedPanel = panel
+			m.moveSelection(up)
+			m.focusedPanel = focused
+		}
+		return nil
+	}
+	if msg.Button != tea.MouseButtonLeft || m.joinGroupLoading || m.sendRequestLoading {
+		return nil
+	}
+
+	if panel != m.focusedPanel {
+		m.focusedPanel = panel
+		m.showReactors = false
+		m.renderMessages()
+	}
+	// Clicking an input is taken as wanting to type into it
+	m.insertMode = isInputPanel(panel) && m.cfg.InputMode == "vim"
+	m.updateFocus()
+
+	switch panel {
+	case CHATS_PANEL:
+		i := listItemAt(m.chatList, y)
+		if m.panes().switcher {
+			i = m.chatSwitcherItemAt(x)
+		}
+		if i == -1 {
+			return nil
+		}
+		m.chatList.Select(i)
+		if chat, ok := m.chatList.SelectedItem().(chatItem); ok {
+			return m.openChat(chat.id)
+		}
+	case ACTIVE_REQUEST_PANEL:
+		if i := listItemAt(m.requestsList, y); i != -1 {
+			m.requestsList.Select(i)
+		}
+	case DETAILS_PANEL:
+		if i := m.detailsMemberAt(y); i != -1 {
+			m.detailsCursor = i
+		}
+	case JOIN_ROOM_PANEL:
+		cmd := m.clickJoinRoom(x, y)
+		m.updateFocus()
+		return cmd
+	}
+	return nil
+}
+
+// Returns the panel at screen cell x, y and the cell's position inside the
+// panel's border. In the chat switcher, the position is the column on the
+// line.
+func (m chatModel) panelAt(x, y int) (panel, px, py int, ok bool) {
+	p := m.panes()
+	if y >= m.bodyHeight() {
+		return 0, 0, 0, false
+	}
+
+	if p.sidebar && x < p.sidebarWidth+2 {
+		px = x - 1
+		boxes := []struct{ panel, height int }{
+			{CHATS_PANEL, m.chatList.Height() + 2},
+		}
+		if p.sidePanels {
+			boxes = append(boxes,
+				struct{ panel, height int }{ACTIVE_REQUEST_PANEL, requestsListHeight + 2},
+				struct{ panel, height int }{SEND_REQUEST_PANNEL, sendRequestHeight},
+				struct{ panel, height int }{JOIN_ROOM_PANEL, joinRoomHeight},
+			)
+		}
+		for _, b := range boxes {
+			if y < b.height {
+				return b.panel, px, y - 1, true
+			}
+			y -= b.height
+		}
+		return 0, 0, 0, false
+	}
+
+	if p.details && x >= m.width-detailsWidth-2 {
+		return DETAILS_PANEL, x - (m.width - detailsWidth - 1), y - 1, true
+	}
+
+	px = x - p.chatX
+	if p.switcher && y == 0 {
+		// The line is indented by a space
+		return CHATS_PANEL, x - 1, 0, true
+	}
+	y -= p.chatY - 1
+	viewHeight := m.shownViewport().Height + 2
+	if y < viewHeight {
+		return MESSSAGE_VIEW_PANEL, px, y - 1, true
+	}
+	return MESSAGE_PANEL, px, y - viewHeight - 1, true
+}
+
+// Returns the index among the visible items of the item at line y of l, or
+// -1 if there is none
+func listItemAt(l list.Model, y int) int {
+	// The title and the status bar each take two lines
+	y -= 2
+	if l.ShowStatusBar() {
+		y -= 2
+	}
+	d := list.NewDefaultDelegate()
+	if y < 0 || y%(d.Height()+d.Spacing()) >= d.Height() {
+		return -1
+	}
+
+	i := l.Paginator.Page*l.Paginator.PerPage + y/(d.Height()+d.Spacing())
+	if i >= len(l.VisibleItems()) || i >= (l.Paginator.Page+1)*l.Paginator.PerPage {
+		return -1
+	}
+	return i
+}
+
+// Returns the index among the visible chats of the chat at column x of the
+// chat switcher, or -1 if there is none
+func (m chatModel) chatSwitcherItemAt(x int) int {
+	if m.chatList.SettingFilter() {
+		return -1
+	}
+	names, start := m.chatSwitcherItems(m.panes().chatWidth)
+	if start > 0 {
+		x -= lipgloss.Width(chatSwitcherMore)
+	}
+	for i := start; i < len(names); i++ {
+		w := lipgloss.Width(names[i])
+		if x < 0 {
+			break
+		}
+		if x < w {
+			return i
+		}
+		x -= w + lipgloss.Width(chatSwitcherSeparator)
+	}
+	return -1
+}
+
+// Focuses the clicked input of the join room panel, or creates or joins the
+// room when a button is clicked
+func (m *chatModel) clickJoinRoom(x, y int) tea.Cmd {
+	// The panel has a title, the name and passkey inputs and then the buttons
+	switch y {
+	case 1, 2:
+		m.joinRoomFocusIndex = y - 1
+		m.groupInputDone = false
+	case 3:
+		create, gap, join := lipgloss.Width("[CREATE]"), 4, lipgloss.Width("[JOIN]")
+		switch {
+		case x >= 0 && x < create:
+			m.groupFocusedBtn = CREATE_GROUP_BTN
+		case x >= create+gap && x < create+gap+join:
+			m.groupFocusedBtn = JOIN_GROUP_BTN
+		default:
+			return nil
+		}
+		// The room needs a name and a passkey before the buttons do anything
+		switch {
+		case m.nameChatInput.Value() == "":
+			m.joinRoomFocusIndex = 0
+		case m.passkeyChatInput.Value() == "":
+			m.joinRoomFocusIndex = 1
+		default:
+			return m.submitJoinRoom()
+		}
+		m.groupInputDone = false
+	}
+	return nil
+}
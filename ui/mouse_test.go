@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Returns a model with three chats, the first of them open with enough
+// messages to scroll, and the details panel shown
+func mouseTestModel() chatModel {
+	var messages []*pb.Message
+	for i := 0; i < 50; i++ {
+		messages = append(messages, testMessage(fmt.Sprint(i), "alice", time.Now().Add(time.Duration(i-50)*time.Minute)))
+	}
+	m := newTestChatModel(
+		chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP, messages: messages},
+		chatItem{id: "b", name: "b", chatType: pb.ChatType_CHAT_TYPE_GROUP},
+		chatItem{id: "c", name: "c", chatType: pb.ChatType_CHAT_TYPE_GROUP},
+	)
+	m.client = &fakeChatClient{stream: &fakeChatStream{}}
+	m.activeChat = "a"
+	m.layout.Details = true
+	m.resize()
+	m.renderMessages()
+	m.viewport.GotoBottom()
+	return m
+}
+
+func click(m chatModel, x, y int) chatModel {
+	model, _ := m.Update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	return model.(chatModel)
+}
+
+func wheel(m chatModel, x, y int, up bool) chatModel {
+	button := tea.MouseButtonWheelDown
+	if up {
+		button = tea.MouseButtonWheelUp
+	}
+	model, _ := m.Update(tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: button})
+	return model.(chatModel)
+}
+
+func TestClickFocusesPanel(t *testing.T) {
+	m := mouseTestModel()
+	p := m.panes()
+	if !p.sidebar || !p.details {
+		t.Fatalf("the test needs the sidebar and the details panel: %+v", p)
+	}
+
+	tests := []struct {
+		name string
+		x, y int
+		want int
+	}{
+		{"chat list", 1, 1, CHATS_PANEL},
+		{"chat view", p.chatX + 1, p.chatY + 1, MESSSAGE_VIEW_PANEL},
+		{"input", p.chatX + 1, m.bodyHeight() - 2, MESSAGE_PANEL},
+		{"details", m.width - 3, 1, DETAILS_PANEL},
+	}
+	for _, tt := range tests {
+		m := mouseTestModel()
+		m.focusedPanel = JOIN_ROOM_PANEL
+		if got := click(m, tt.x, tt.y).focusedPanel; got != tt.want {
+			t.Errorf("clicking the %s focused panel %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClickSelectsChat(t *testing.T) {
+	// Inside the border, the title takes two lines and each chat three
+	m := click(mouseTestModel(), 1, 1+2+3)
+	if got := m.selectedChatID(); got != "b" {
+		t.Errorf("clicking the second chat selected %q", got)
+	}
+	if m.activeChat != "b" {
+		t.Errorf("clicking the second chat opened %q", m.activeChat)
+	}
+}
+
+func TestWheelScrolls(t *testing.T) {
+	m := mouseTestModel()
+	m.focusedPanel = MESSAGE_PANEL
+	p := m.panes()
+	bottom := m.viewport.YOffset
+
+	m = wheel(m, p.chatX+1, p.chatY+1, true)
+	if got := m.viewport.YOffset; got != bottom-wheelLines {
+		t.Errorf("the chat view is at line %d after scrolling up, want %d", got, bottom-wheelLines)
+	}
+	m = wheel(m, p.chatX+1, p.chatY+1, false)
+	if got := m.viewport.YOffset; got != bottom {
+		t.Errorf("the chat view is at line %d after scrolling back down, want %d", got, bottom)
+	}
+
+	m = wheel(m, 1, 1, false)
+	if got := m.chatList.Index(); got != 1 {
+		t.Errorf("chat %d is selected after scrolling the chat list, want 1", got)
+	}
+	if m.focusedPanel != MESSAGE_PANEL {
+		t.Errorf("scrolling focused panel %d", m.focusedPanel)
+	}
+}
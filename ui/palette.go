@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sahilm/fuzzy"
+)
+
+const (
+	PALETTE_OPEN_CHAT = iota
+	PALETTE_ACCEPT_REQUEST
+	PALETTE_REJECT_REQUEST
+	PALETTE_CREATE_GROUP
+	PALETTE_JOIN_GROUP
+	PALETTE_SEND_REQUEST
+	PALETTE_EXPORT
+	PALETTE_LINKS
+	PALETTE_COMMANDS
+	PALETTE_TOGGLE_HELP
+	PALETTE_TOGGLE_SIDEBAR
+	PALETTE_ZEN
+	PALETTE_TOGGLE_DETAILS
+	PALETTE_LOGOUT
+	PALETTE_QUIT
+)
+
+type paletteItem struct {
+	action int
+	title  string
+	key    string // keybinding of the action, if any
+	index  int    // index of the request the action is for
+	chatID string // chat the action is for
+}
+
+type paletteItems []paletteItem
+
+func (p paletteItems) String(i int) string { return p[i].title }
+func (p paletteItems) Len() int            { return len(p) }
+
+func newPaletteInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "Type a chat, request or action"
+	ti.Prompt = "> "
+	return ti
+}
+
+// Lists the chats, pending requests and actions the palette can run
+func (m chatModel) paletteItems() paletteItems {
+	var items paletteItems
+	for _, v := range m.chatList.Items() {
+		chat := v.(chatItem)
+		items = append(items, paletteItem{action: PALETTE_OPEN_CHAT, title: "Open " + chat.displayName(), chatID: chat.id})
+	}
+	for i, v := range m.requestsList.Items() {
+		name := v.(requestItem).name
+		items = append(items,
+			paletteItem{action: PALETTE_ACCEPT_REQUEST, title: "Accept request from " + name, key: m.keys.Accept.Help().Key, index: i},
+			paletteItem{action: PALETTE_REJECT_REQUEST, title: "Reject request from " + name, key: m.keys.Reject.Help().Key, index: i},
+		)
+	}
+	return append(items,
+		paletteItem{action: PALETTE_CREATE_GROUP, title: "Create a group", key: "/create"},
+		paletteItem{action: PALETTE_JOIN_GROUP, title: "Join a group", key: "/join"},
+		paletteItem{action: PALETTE_SEND_REQUEST, title: "Send a chat request", key: "/msg"},
+		paletteItem{action: PALETTE_EXPORT, title: "Export chat", key: m.keys.Export.Help().Key},
+		paletteItem{action: PALETTE_LINKS, title: "Open or copy a link", key: m.keys.Links.Help().Key},
+		paletteItem{action: PALETTE_COMMANDS, title: "List commands", key: "/help"},
+		paletteItem{action: PALETTE_TOGGLE_HELP, title: "Toggle help", key: m.keys.Help.Help().Key},
+		paletteItem{action: PALETTE_TOGGLE_SIDEBAR, title: "Toggle sidebar", key: m.keys.ToggleSidebar.Help().Key},
+		paletteItem{action: PALETTE_ZEN, title: "Toggle zen mode", key: m.keys.Zen.Help().Key},
+		paletteItem{action: PALETTE_TOGGLE_DETAILS, title: "Toggle chat details", key: m.keys.ToggleDetails.Help().Key},
+		paletteItem{action: PALETTE_LOGOUT, title: "Log out"},
+		paletteItem{action: PALETTE_QUIT, title: "Quit", key: m.keys.Quit.Help().Key},
+	)
+}
+
+func (m *chatModel) openPalette() tea.Cmd {
+	m.palette = true
+	m.paletteIdx = 0
+	m.paletteInput.Reset()
+	m.paletteMatches = m.paletteItems()
+	return m.paletteInput.Focus()
+}
+
+// Filters the palette items by the query typed
+func (m *chatModel) filterPalette() {
+	items := m.paletteItems()
+	query := m.paletteInput.Value()
+	m.paletteIdx = 0
+	if query == "" {
+		m.paletteMatches = items
+		return
+	}
+
+	m.paletteMatches = nil
+	for _, match := range fuzzy.FindFrom(query, items) {
+		m.paletteMatches = append(m.paletteMatches, items[match.Index])
+	}
+}
+
+func (m chatModel) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.paletteIdx > 0 {
+			m.paletteIdx--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.paletteIdx < len(m.paletteMatches)-1 {
+			m.paletteIdx++
+		}
+	case key.Matches(msg, m.keys.Cancel, m.keys.Palette):
+		m.palette = false
+		m.paletteInput.Blur()
+		return m, m.drawOverlays()
+	case key.Matches(msg, m.keys.Enter):
+		m.palette = false
+		m.paletteInput.Blur()
+		if m.paletteIdx >= len(m.paletteMatches) {
+			return m, nil
+		}
+		return m.runPaletteItem(m.paletteMatches[m.paletteIdx])
+	default:
+		query := m.paletteInput.Value()
+		m.paletteInput, cmd = m.paletteInput.Update(msg)
+		if m.paletteInput.Value() != query {
+			m.filterPalette()
+		}
+	}
+
+	return m, cmd
+}
+
+func (m chatModel) runPaletteItem(item paletteItem) (tea.Model, tea.Cmd) {
+	switch item.action {
+	case PALETTE_OPEN_CHAT:
+		return m, m.openChat(item.chatID)
+	case PALETTE_ACCEPT_REQUEST:
+		return m, m.answerRequest(item.index, pb.DirectChatAction_ACTION_ACCEPT)
+	case PALETTE_REJECT_REQUEST:
+		return m, m.answerRequest(item.index, pb.DirectChatAction_ACTION_REJECT)
+	case PALETTE_CREATE_GROUP, PALETTE_JOIN_GROUP, PALETTE_SEND_REQUEST:
+		// The command is completed in the message input
+		m.focusedPanel = MESSAGE_PANEL
+		m.insertMode = true
+		m.input.Reset()
+		m.input.InsertString(item.key + " ")
+		m.fitInput()
+	case PALETTE_EXPORT:
+		_, chat, ok := m.activeChatItem()
+		if !ok {
+			m.msg = "No chat to export"
+			break
+		}
+		return m, m.exportChat(chat, m.cfg.downloadDir())
+	case PALETTE_LINKS:
+		m.openLinkPicker()
+		return m, nil
+	case PALETTE_COMMANDS:
+		m.commandHelp = true
+		return m, nil
+	case PALETTE_TOGGLE_HELP:
+		m.help.ShowAll = !m.help.ShowAll
+		m.resize()
+	case PALETTE_TOGGLE_SIDEBAR:
+		prefs := m.layout
+		prefs.SidebarCollapsed = !prefs.SidebarCollapsed
+		prefs.Zen = false
+		return m, tea.Batch(m.setLayout(prefs), m.drawOverlays())
+	case PALETTE_ZEN:
+		prefs := m.layout
+		prefs.Zen = !prefs.Zen
+		return m, tea.Batch(m.setLayout(prefs), m.drawOverlays())
+	case PALETTE_TOGGLE_DETAILS:
+		return m, tea.Batch(m.toggleDetails(), m.drawOverlays())
+	case PALETTE_LOGOUT:
+		return m.logout()
+	case PALETTE_QUIT:
+		if m.chatWriter != nil {
+			m.chatWriter.close()
+		}
+		return m, tea.Quit
+	}
+	return m, m.drawOverlays()
+}
+
+// Ends the session, going back to the login screen. The streams and
+// requests of the session are cancelled.
+func (m chatModel) logout() (tea.Model, tea.Cmd) {
+	m.endSession()
+
+	login := NewLoginModel(m.user.Username, m.client, m.cfg)
+	login.width, login.height = m.width, m.height
+	return login, tea.Batch(tea.ExitAltScreen, tea.DisableMouse, textinput.Blink)
+}
+
+// Renders the palette to fill the viewport
+func (m chatModel) formatPalette(width, height int) string {
+	lines := []string{m.paletteInput.View()}
+	if len(m.paletteMatches) == 0 {
+		lines = append(lines, suggestionStyle.Render("No matches"))
+	}
+
+	// Scroll so the selected item is always shown
+	rows := max(1, height-1)
+	start := max(0, m.paletteIdx-rows+1)
+	end := min(len(m.paletteMatches), start+rows)
+	for i := start; i < end; i++ {
+		item := m.paletteMatches[i]
+		key := suggestionDescStyle.Render(item.key)
+		title := runewidth.Truncate(item.title, max(1, width-lipgloss.Width(key)-3), "…")
+		gap := strings.Repeat(" ", max(1, width-lipgloss.Width(title)-lipgloss.Width(key)-2))
+		if i == m.paletteIdx {
+			lines = append(lines, selectedSuggestionStyle.Render("> "+title)+gap+key)
+			continue
+		}
+		lines = append(lines, suggestionStyle.Render("  "+title)+gap+key)
+	}
+	return defaultStyle.Copy().Width(width).Height(height).MaxHeight(height).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPaletteOpensWithCtrlP(t *testing.T) {
+	m := newTestChatModel()
+	m.focusedPanel = MESSAGE_PANEL
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	if !model.(chatModel).palette {
+		t.Error("ctrl+p did not open the palette")
+	}
+}
+
+func TestPaletteExportsChat(t *testing.T) {
+	m := newTestChatModel(chatItem{id: "a", name: "team", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.cfg.DownloadDir = t.TempDir()
+
+	var export paletteItem
+	for _, item := range m.paletteItems() {
+		if item.action == PALETTE_EXPORT {
+			export = item
+		}
+	}
+	if export.action != PALETTE_EXPORT {
+		t.Fatal("the palette has no export action")
+	}
+
+	tests := []struct {
+		name   string
+		active string
+		files  int
+	}{
+		{"no chat open", "", 0},
+		{"chat open", "a", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.activeChat = tt.active
+			_, cmd := m.runPaletteItem(export)
+			runCmd(cmd)
+			files, _ := filepath.Glob(filepath.Join(m.cfg.DownloadDir, "team *.md"))
+			if len(files) != tt.files {
+				t.Errorf("%d chats exported, want %d", len(files), tt.files)
+			}
+			for _, f := range files {
+				os.Remove(f)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	defaultSidebarWidth = 31
+	minSidebarWidth     = 20
+	sidebarWidthStep    = 2
+	// Narrowest the chat gets before the sidebar collapses into a switcher
+	minChatWidth = 40
+	// Fewest chats listed before the panels under the chat list are hidden
+	minChatListHeight = 6
+
+	requestsListHeight = 8
+	sendRequestHeight  = 5 // including the borders
+	joinRoomHeight     = 6 // including the borders
+)
+
+// Layout preferences, kept between sessions
+type layoutPrefs struct {
+	SidebarWidth     int  `json:"sidebar_width"`
+	SidebarCollapsed bool `json:"sidebar_collapsed"`
+	Zen              bool `json:"zen"` // only the chat is shown
+	Details          bool `json:"details"`
+}
+
+// Where the panels go for the current window size and preferences
+type panes struct {
+	sidebar      bool // the chat list is shown beside the chat
+	sidePanels   bool // the requests, send request and join room panels are shown under the chat list
+	switcher     bool // the chat list is collapsed into a line above the chat
+	details      bool // the details of the open chat are shown right of the chat
+	help         bool
+	sidebarWidth int // inside the borders
+	chatWidth    int // inside the borders
+	chatX        int // screen column the chat view starts at, inside its border
+	chatY        int // screen line the chat view starts at, inside its border
+}
+
+// Keeps a sidebar width chosen by the user wide enough to use and narrow
+// enough to leave room for the chat. Narrow windows get the narrowest
+// sidebar; it is collapsed until the window is wide enough.
+func clampSidebarWidth(w, windowWidth int) int {
+	return max(min(w, windowWidth-minChatWidth-4), minSidebarWidth)
+}
+
+func (m chatModel) panes() panes {
+	p := panes{help: !m.layout.Zen}
+
+	p.sidebarWidth = min(max(m.layout.SidebarWidth, minSidebarWidth), m.width/2)
+	p.sidebar = !m.layout.Zen && !m.layout.SidebarCollapsed && m.width-p.sidebarWidth-4 >= minChatWidth
+	p.switcher = !m.layout.Zen && !p.sidebar
+	if !p.sidebar {
+		p.sidebarWidth = 0
+	}
+
+	// The chat list, requests list and both forms each have a border
+	listHeight := m.bodyHeight() - 2 - requestsListHeight - 2 - sendRequestHeight - joinRoomHeight
+	p.sidePanels = p.sidebar && listHeight >= minChatListHeight
+
+	p.chatWidth = m.width - 2
+	p.chatX, p.chatY = 1, 1
+	if p.sidebar {
+		p.chatWidth -= p.sidebarWidth + 2
+		p.chatX += p.sidebarWidth + 2
+	}
+	p.details = !m.layout.Zen && m.layout.Details && p.chatWidth-detailsWidth-2 >= minChatWidth
+	if p.details {
+		p.chatWidth -= detailsWidth + 2
+	}
+	if p.switcher {
+		p.chatY++
+	}
+	return p
+}
+
+// Number of lines left for the panels once the status line and the help are
+// shown
+func (m chatModel) bodyHeight() int {
+	h := m.height - 1
+	if !m.layout.Zen {
+		h -= lipgloss.Height(m.help.View(m.keys))
+	}
+	return h
+}
+
+// Checks if a panel can be focused in the current layout
+func (m chatModel) panelVisible(panel int) bool {
+	p := m.panes()
+	switch panel {
+	case CHATS_PANEL:
+		return !m.layout.Zen
+	case ACTIVE_REQUEST_PANEL, SEND_REQUEST_PANNEL, JOIN_ROOM_PANEL:
+		return p.sidePanels
+	case DETAILS_PANEL:
+		return p.details
+	}
+	return true
+}
+
+// Moves the focus to the next visible panel, or the previous one if dir is -1
+func (m *chatModel) cyclePanels(dir int) {
+	for i := 0; i < MAX_PANEL_NO; i++ {
+		m.focusedPanel = (m.focusedPanel + dir + MAX_PANEL_NO) % MAX_PANEL_NO
+		if m.panelVisible(m.focusedPanel) {
+			return
+		}
+	}
+}
+
+// Sizes every panel to fit the window
+func (m *chatModel) resize() {
+	p := m.panes()
+	body := m.bodyHeight()
+	m.help.Width = m.width
+
+	if p.sidebar {
+		listHeight := body - 2
+		if p.sidePanels {
+			listHeight -= requestsListHeight + 2 + sendRequestHeight + joinRoomHeight
+		}
+		m.chatList.SetSize(p.sidebarWidth, listHeight)
+		m.requestsList.SetSize(p.sidebarWidth, requestsListHeight)
+		m.addUserInput.Width = p.sidebarWidth - 3
+		m.nameChatInput.Width = p.sidebarWidth - 3
+		m.passkeyChatInput.Width = p.sidebarWidth - 3
+	} else {
+		// The list is still used to move through the chats in the switcher
+		m.chatList.SetSize(p.chatWidth, max(1, body-2))
+	}
+
+	m.input.SetWidth(p.chatWidth)
+	m.viewport.Width = p.chatWidth
+
+	atBottom := m.viewport.AtBottom()
+	m.viewport.Height = max(1, body-2-lipgloss.Height(m.input.View())-2-(p.chatY-1))
+	m.renderMessages()
+	if atBottom && m.focusedPanel != MESSSAGE_VIEW_PANEL {
+		m.viewport.GotoBottom()
+	}
+
+	if !m.panelVisible(m.focusedPanel) {
+		m.focusedPanel = MESSAGE_PANEL
+	}
+}
+
+// Changes the layout preferences and lays the panels out again
+func (m *chatModel) setLayout(prefs layoutPrefs) tea.Cmd {
+	m.layout = prefs
+	m.resize()
+	return saveLayoutPrefs(prefs)
+}
+
+// Renders the chats as a single line, scrolled so the selected chat shows.
+// It stands in for the chat list when the window is too narrow for it.
+func (m chatModel) formatChatSwitcher(width int) string {
+	if m.chatList.SettingFilter() {
+		return m.chatList.FilterInput.View()
+	}
+
+	names, start := m.chatSwitcherItems(width)
+	if len(names) == 0 {
+		return suggestionStyle.Render("No chats")
+	}
+	line := strings.Join(names[start:], chatSwitcherSeparator)
+	if start > 0 {
+		line = chatSwitcherMore + line
+	}
+	if lipgloss.Width(line) > width {
+		line = cutCells(line, 0, width-1) + "›"
+	}
+	return line
+}
+
+const (
+	chatSwitcherSeparator = " │ "
+	chatSwitcherMore      = "‹ "
+)
+
+// Returns the chats in the switcher and the first one shown
+func (m chatModel) chatSwitcherItems(width int) ([]string, int) {
+	items := m.chatList.VisibleItems()
+	cursor := m.chatList.Index()
+
+	names := make([]string, len(items))
+	for i, v := range items {
+		chat := v.(chatItem)
+		name := runewidth.Truncate(chat.displayName(), 20, "…")
+		if chat.unread > 0 {
+			name += fmt.Sprintf(" (%d)", chat.unread)
+		}
+		style := suggestionStyle
+		if chat.id == m.activeChat {
+			style = senderTextStyle
+		}
+		if i == cursor && m.focusedPanel == CHATS_PANEL {
+			style = style.Copy().Underline(true)
+		}
+		names[i] = style.Render(name)
+		if chat.chatType == pb.ChatType_CHAT_TYPE_DIRECT {
+			if icon := m.formatPresenceIcon(chat.partner); icon != "" {
+				names[i] = icon + " " + names[i]
+			}
+		}
+	}
+
+	// Drop chats from the start until the selected one fits
+	start := 0
+	for start < cursor && lipgloss.Width(strings.Join(names[start:cursor+1], chatSwitcherSeparator)) > width-2 {
+		start++
+	}
+	return names, start
+}
+
+func layoutPrefsPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cli-chat", "layout.json"), nil
+}
+
+// Returns the layout preferences saved by previous sessions
+func loadLayoutPrefs() layoutPrefs {
+	prefs := layoutPrefs{SidebarWidth: defaultSidebarWidth}
+	path, err := layoutPrefsPath()
+	if err != nil {
+		return prefs
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return prefs
+	}
+	json.Unmarshal(b, &prefs)
+	return prefs
+}
+
+func saveLayoutPrefs(prefs layoutPrefs) tea.Cmd {
+	return func() tea.Msg {
+		// Losing the layout is not worth interrupting the user
+		path, err := layoutPrefsPath()
+		if err != nil {
+			return nil
+		}
+		b, err := json.Marshal(prefs)
+		if err != nil {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil
+		}
+		os.WriteFile(path, b, 0644)
+		return nil
+	}
+}
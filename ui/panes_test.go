@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestClampSidebarWidth(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		window int
+		want   int
+	}{
+		{"fits", 30, 120, 30},
+		{"too narrow", 5, 120, minSidebarWidth},
+		{"too wide", 100, 120, 120 - minChatWidth - 4},
+		{"narrow window", 30, 50, minSidebarWidth},
+		{"tiny window", 30, 10, minSidebarWidth},
+	}
+	for _, tt := range tests {
+		if got := clampSidebarWidth(tt.width, tt.window); got != tt.want {
+			t.Errorf("%s: clampSidebarWidth(%d, %d) = %d, want %d", tt.name, tt.width, tt.window, got, tt.want)
+		}
+	}
+}
+
+func TestSidebarKeys(t *testing.T) {
+	// The layout is saved to the cache
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	tests := []struct {
+		name  string
+		key   tea.KeyMsg
+		delta int
+	}{
+		{"word left", tea.KeyMsg{Type: tea.KeyCtrlLeft}, 0},
+		{"word right", tea.KeyMsg{Type: tea.KeyCtrlRight}, 0},
+		{"shrink", tea.KeyMsg{Type: tea.KeyF7}, -sidebarWidthStep},
+		{"grow", tea.KeyMsg{Type: tea.KeyF8}, sidebarWidthStep},
+	}
+	for _, tt := range tests {
+		m := newTestChatModel()
+		m.focusedPanel = MESSAGE_PANEL
+		before := m.panes().sidebarWidth
+
+		model, _ := m.Update(tt.key)
+		m = model.(chatModel)
+		if got := m.panes().sidebarWidth - before; got != tt.delta {
+			t.Errorf("%s: sidebar width changed by %d, want %d", tt.name, got, tt.delta)
+		}
+	}
+}
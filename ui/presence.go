@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+	"google.golang.org/grpc/metadata"
+)
+
+// How often the user is checked for being idle
+const presenceTickInterval = 15 * time.Second
+
+// Opens the presence stream, which publishes the user's presence and
+// receives the presence of everyone they share a chat with
+func (m chatModel) openPresence() tea.Cmd {
+	return func() tea.Msg {
+		meta := metadata.Pairs("authorization", fmt.Sprintf("Bearer %s", m.sessionToken))
+		ctx := metadata.NewOutgoingContext(m.session, meta)
+
+		stream, err := m.client.PresenceStream(ctx)
+		if err != nil {
+			// Presence is left out rather than interrupting the user
+			return nil
+		}
+		return statusMsg{sType: STATUS_PRESENCE_OPEN, sRes: stream}
+	}
+}
+
+// Publishes the user's presence if it changed
+func (m *chatModel) setPresence(state pb.PresenceState) tea.Cmd {
+	if m.presenceStream == nil || m.presenceState == state {
+		return nil
+	}
+	m.presenceState = state
+
+	stream := m.presenceStream
+	return func() tea.Msg {
+		// A failed send closes the stream, which recvPresence reports
+		stream.Send(&pb.PresenceUpdate{State: state})
+		return nil
+	}
+}
+
+func (m chatModel) recvPresence() tea.Cmd {
+	stream, ch, session := m.presenceStream, m.presenceChan, m.session
+	return func() tea.Msg {
+		for {
+			p, err := stream.Recv()
+			if err != nil {
+				return statusMsg{sType: STATUS_PRESENCE_CLOSE}
+			}
+			select {
+			case ch <- p:
+			case <-session.Done():
+				return nil
+			}
+		}
+	}
+}
+
+func (m chatModel) waitPresence() tea.Cmd {
+	ch, session := m.presenceChan, m.session
+	return func() tea.Msg {
+		select {
+		case p := <-ch:
+			return statusMsg{sRes: p, sType: STATUS_PRESENCE_RECV}
+		case <-session.Done():
+			return nil
+		}
+	}
+}
+
+func presenceTick() tea.Cmd {
+	return tea.Tick(presenceTickInterval, func(time.Time) tea.Msg {
+		return statusMsg{sType: STATUS_PRESENCE_TICK}
+	})
+}
+
+// Handles the presence stream's messages and ticks
+func (m *chatModel) updatePresence(msg statusMsg) tea.Cmd {
+	switch msg.sType {
+	case STATUS_PRESENCE_OPEN:
+		m.presenceStream = msg.sRes.(pb.ChatService_PresenceStreamClient)
+		return tea.Batch(m.setPresence(pb.PresenceState_PRESENCE_STATE_ONLINE), m.recvPresence(), m.waitPresence(), presenceTick())
+	case STATUS_PRESENCE_RECV:
+		p := msg.sRes.(*pb.Presence)
+		m.presence[p.Username] = p
+		for i, v := range m.chatList.Items() {
+			chat := v.(chatItem)
+			if chat.chatType == pb.ChatType_CHAT_TYPE_DIRECT && chat.partner == p.Username {
+				chat.presence = p.State
+				m.chatList.SetItem(i, chat)
+			}
+		}
+		return m.waitPresence()
+	case STATUS_PRESENCE_CLOSE:
+		// Presence that can no longer be followed is not shown
+		m.presenceStream = nil
+		m.presenceState = pb.PresenceState_PRESENCE_STATE_UNSPECIFIED
+		m.presence = map[string]*pb.Presence{}
+		for i, v := range m.chatList.Items() {
+			chat := v.(chatItem)
+			chat.presence = pb.PresenceState_PRESENCE_STATE_UNSPECIFIED
+			m.chatList.SetItem(i, chat)
+		}
+	case STATUS_PRESENCE_TICK:
+		if m.presenceStream == nil {
+			return nil
+		}
+		if m.cfg.awayAfter > 0 && time.Since(m.lastInput) >= m.cfg.awayAfter {
+			return tea.Batch(m.setPresence(pb.PresenceState_PRESENCE_STATE_AWAY), presenceTick())
+		}
+		return presenceTick()
+	}
+	return nil
+}
+
+// Notes that the user is around. When they were away, coming back is
+// published before msg is handled.
+func (m *chatModel) noteInput(msg tea.Msg) tea.Cmd {
+	m.lastInput = time.Now()
+	if m.presenceState != pb.PresenceState_PRESENCE_STATE_AWAY {
+		return nil
+	}
+	return tea.Sequence(
+		inSession(m.session, m.setPresence(pb.PresenceState_PRESENCE_STATE_ONLINE)),
+		func() tea.Msg { return sessionMsg{session: m.session, msg: msg} },
+	)
+}
+
+// Returns the icon of a presence state. The states differ in shape as well as
+// colour so they can be told apart without colours.
+func presenceIcon(state pb.PresenceState) string {
+	switch state {
+	case pb.PresenceState_PRESENCE_STATE_ONLINE:
+		return "●"
+	case pb.PresenceState_PRESENCE_STATE_AWAY:
+		return "◐"
+	case pb.PresenceState_PRESENCE_STATE_OFFLINE:
+		return "○"
+	}
+	return ""
+}
+
+// Renders the coloured presence icon of username, or nothing if their
+// presence is not known
+func (m chatModel) formatPresenceIcon(username string) string {
+	p, ok := m.presence[username]
+	if username == m.user.Username && m.presenceStream != nil {
+		p, ok = &pb.Presence{State: m.presenceState}, true
+	}
+	if !ok {
+		return ""
+	}
+	icon := presenceIcon(p.State)
+	switch p.State {
+	case pb.PresenceState_PRESENCE_STATE_ONLINE:
+		return successTextStyle.Render(icon)
+	case pb.PresenceState_PRESENCE_STATE_AWAY:
+		return notificationTextStyle.Render(icon)
+	}
+	return suggestionStyle.Render(icon)
+}
+
+// Describes the presence of username, e.g. Online or Last seen 5m ago
+func (m chatModel) formatPresence(username string) string {
+	p, ok := m.presence[username]
+	if !ok {
+		return ""
+	}
+	switch p.State {
+	case pb.PresenceState_PRESENCE_STATE_ONLINE:
+		return "Online"
+	case pb.PresenceState_PRESENCE_STATE_AWAY:
+		return "Away"
+	}
+	if p.LastSeen == nil {
+		return "Offline"
+	}
+	return "Last seen " + formatAgo(p.LastSeen.AsTime())
+}
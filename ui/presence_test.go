@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	"google.golang.org/grpc"
+)
+
+// A presence stream that receives nothing
+type fakePresenceStream struct {
+	grpc.ClientStream
+	sent []*pb.PresenceUpdate
+}
+
+func (s *fakePresenceStream) Send(u *pb.PresenceUpdate) error {
+	s.sent = append(s.sent, u)
+	return nil
+}
+
+func (s *fakePresenceStream) Recv() (*pb.Presence, error) { return nil, io.EOF }
+
+// A client whose presence stream is fakePresenceStream
+type fakePresenceClient struct {
+	pb.ChatServiceClient
+	stream *fakePresenceStream
+	opened int
+}
+
+func (c *fakePresenceClient) PresenceStream(ctx context.Context, opts ...grpc.CallOption) (pb.ChatService_PresenceStreamClient, error) {
+	c.opened++
+	return c.stream, nil
+}
+
+func TestPresenceOpensOutsideUpdate(t *testing.T) {
+	client := &fakePresenceClient{stream: &fakePresenceStream{}}
+	m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.client = client
+	m.activeChat = "a"
+
+	// Nothing is opened while a message is handled
+	model, _ := m.Update(statusMsg{sType: STATUS_MESSAGE_RECV, sRes: &pb.MessageStream{ChatId: "a", Message: testMessage("1", "alice", time.Now())}})
+	m = model.(chatModel)
+	if client.opened != 0 {
+		t.Fatal("the presence stream was opened in Update")
+	}
+	if len(m.activeMessages()) != 1 {
+		t.Fatal("the first message was not handled")
+	}
+
+	opened, ok := m.openPresence()().(statusMsg)
+	if client.opened != 1 || !ok || opened.sType != STATUS_PRESENCE_OPEN {
+		t.Fatalf("the presence stream was not opened")
+	}
+
+	model, cmd := m.Update(opened)
+	m = model.(chatModel)
+	if m.presenceStream == nil || cmd == nil {
+		t.Fatal("the opened stream was not used")
+	}
+}
@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Emojis offered by the reaction picker, in display order
+var reactionEmojis = []string{"👍", "👎", "😂", "❤️", "🎉", "😮", "😢", "👀"}
+
+type reactionCount struct {
+	emoji string
+	users []string
+}
+
+// Groups the reactions on a message by emoji, keeping the order in which
+// each emoji was first used
+func aggregateReactions(reactions []*pb.Reaction) []reactionCount {
+	var counts []reactionCount
+	idx := map[string]int{}
+
+	for _, r := range reactions {
+		i, ok := idx[r.Emoji]
+		if !ok {
+			i = len(counts)
+			idx[r.Emoji] = i
+			counts = append(counts, reactionCount{emoji: r.Emoji})
+		}
+		counts[i].users = append(counts[i].users, r.User.GetUsername())
+	}
+	return counts
+}
+
+// Adds or removes a reaction on the message it targets. Returns false if the
+// message is not in msgs.
+func applyReaction(msgs []*pb.Message, r *pb.Reaction) bool {
+	for _, msg := range msgs {
+		if msg.Id != r.MessageId {
+			continue
+		}
+		for i, v := range msg.Reactions {
+			if v.Emoji == r.Emoji && v.User.GetUsername() == r.User.GetUsername() {
+				if r.Removed {
+					msg.Reactions = append(msg.Reactions[:i], msg.Reactions[i+1:]...)
+				}
+				return true
+			}
+		}
+		if !r.Removed {
+			msg.Reactions = append(msg.Reactions, r)
+		}
+		return true
+	}
+	return false
+}
+
+func hasReacted(msg *pb.Message, emoji, username string) bool {
+	for _, r := range msg.Reactions {
+		if r.Emoji == emoji && r.User.GetUsername() == username {
+			return true
+		}
+	}
+	return false
+}
+
+// Renders the aggregated counts shown under a message i.e. 👍 3  🎉 1
+func (m chatModel) formatReactions(msg *pb.Message) string {
+	var counts []string
+	for _, c := range aggregateReactions(msg.Reactions) {
+		style := reactionTextStyle
+		if hasReacted(msg, c.emoji, m.user.Username) {
+			style = selfReactionTextStyle
+		}
+		counts = append(counts, style.Render(fmt.Sprintf("%s %d", c.emoji, len(c.users))))
+	}
+	return strings.Join(counts, "  ")
+}
+
+// Renders the list of users behind each reaction on a message
+func (m chatModel) formatReactors(msg *pb.Message) string {
+	counts := aggregateReactions(msg.Reactions)
+	if len(counts) == 0 {
+		return reactionTextStyle.Render("No reactions yet")
+	}
+
+	var lines []string
+	for _, c := range counts {
+		lines = append(lines, fmt.Sprintf("%s %s", c.emoji, reactionTextStyle.Render(strings.Join(c.users, ", "))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m chatModel) formatReactionPicker(msg *pb.Message) string {
+	var options []string
+	for i, e := range reactionEmojis {
+		style := reactionTextStyle
+		if hasReacted(msg, e, m.user.Username) {
+			style = selfReactionTextStyle
+		}
+		if i == m.reactionPickerIdx {
+			options = append(options, style.Render("["+e+"]"))
+			continue
+		}
+		options = append(options, style.Render(" "+e+" "))
+	}
+	return strings.Join(options, " ")
+}
+
+// Creates the stream event that toggles the reaction of the current user
+// on a message
+func (m chatModel) toggleReaction(chatID string, msg *pb.Message, emoji string) *pb.MessageStream {
+	return &pb.MessageStream{
+		ChatId: chatID,
+		Reaction: &pb.Reaction{
+			MessageId: msg.Id,
+			Emoji:     emoji,
+			User:      m.user,
+			Removed:   hasReacted(msg, emoji, m.user.Username),
+			SentAt:    timestamppb.Now(),
+		},
+	}
+}
+
+func (m chatModel) updateReactionPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch {
+	case key.Matches(msg, m.keys.Left):
+		if m.reactionPickerIdx == 0 {
+			m.reactionPickerIdx = len(reactionEmojis) - 1
+		} else {
+			m.reactionPickerIdx--
+		}
+	case key.Matches(msg, m.keys.Right):
+		if m.reactionPickerIdx == len(reactionEmojis)-1 {
+			m.reactionPickerIdx = 0
+		} else {
+			m.reactionPickerIdx++
+		}
+	case key.Matches(msg, m.keys.Enter):
+		m.reactionPicker = false
+
+		msgs := m.activeMessages()
+		if m.chatStream != nil && m.selectedMsg < len(msgs) {
+			cmd = m.send(m.toggleReaction(m.activeChat, msgs[m.selectedMsg], reactionEmojis[m.reactionPickerIdx]))
+		}
+	case key.Matches(msg, m.keys.Cancel):
+		m.reactionPicker = false
+	}
+	m.renderMessages()
+
+	return m, cmd
+}
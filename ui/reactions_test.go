@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Ayobami0/cli-chat/pb"
+)
+
+func reaction(emoji, username string) *pb.Reaction {
+	return &pb.Reaction{MessageId: "1", Emoji: emoji, User: &pb.User{Username: username}}
+}
+
+func TestAggregateReactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		reactions []*pb.Reaction
+		want      []reactionCount
+	}{
+		{"none", nil, nil},
+		{"one", []*pb.Reaction{reaction("👍", "alice")}, []reactionCount{{"👍", []string{"alice"}}}},
+		{
+			"grouped in order of first use",
+			[]*pb.Reaction{reaction("🎉", "alice"), reaction("👍", "bob"), reaction("🎉", "carol")},
+			[]reactionCount{{"🎉", []string{"alice", "carol"}}, {"👍", []string{"bob"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aggregateReactions(tt.reactions); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("aggregateReactions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyReaction(t *testing.T) {
+	removed := reaction("👍", "alice")
+	removed.Removed = true
+	other := reaction("👍", "alice")
+	other.MessageId = "2"
+
+	tests := []struct {
+		name     string
+		existing []*pb.Reaction
+		r        *pb.Reaction
+		found    bool
+		want     []string // emoji and username of the reactions left
+	}{
+		{"added", nil, reaction("👍", "alice"), true, []string{"👍alice"}},
+		{"added again", []*pb.Reaction{reaction("👍", "alice")}, reaction("👍", "alice"), true, []string{"👍alice"}},
+		{"other emoji", []*pb.Reaction{reaction("👍", "alice")}, reaction("🎉", "alice"), true, []string{"👍alice", "🎉alice"}},
+		{"removed", []*pb.Reaction{reaction("👍", "bob"), reaction("👍", "alice")}, removed, true, []string{"👍bob"}},
+		{"removed without reacting", []*pb.Reaction{reaction("👍", "bob")}, removed, true, []string{"👍bob"}},
+		{"other message", []*pb.Reaction{reaction("👍", "bob")}, other, false, []string{"👍bob"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &pb.Message{Id: "1", Reactions: tt.existing}
+			if found := applyReaction([]*pb.Message{msg}, tt.r); found != tt.found {
+				t.Errorf("applyReaction = %v, want %v", found, tt.found)
+			}
+			var got []string
+			for _, r := range msg.Reactions {
+				got = append(got, r.Emoji+r.User.GetUsername())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reactions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
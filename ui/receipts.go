@@ -0,0 +1,303 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/google/uuid"
+	"github.com/mattn/go-runewidth"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// A message sent by the user that has not been streamed back yet
+type pendingMessage struct {
+	msg    *pb.Message
+	acked  bool // stored by the server
+	failed bool
+}
+
+// Sends a message to the active chat. It is shown as sending until the
+// server streams it back.
+func (m *chatModel) sendMessage(content string) tea.Cmd {
+	msg := &pb.Message{
+		// The id is made here so the server can tell a retried message from
+		// a new one
+		Id:      uuid.Must(uuid.NewV7()).String(),
+		Sender:  m.user,
+		Content: expandShortcodes(content),
+		SentAt:  timestamppb.Now(),
+		Type:    pb.Message_MESSAGE_TYPE_REGULAR,
+	}
+	m.pending = append(m.pending, pendingMessage{msg: msg})
+	m.renderMessages()
+	m.viewport.GotoBottom()
+
+	return m.sendPending(msg)
+}
+
+func (m chatModel) sendPending(msg *pb.Message) tea.Cmd {
+	sent := m.chatWriter.send(&pb.MessageStream{ChatId: m.activeChat, Message: msg})
+	return func() tea.Msg {
+		select {
+		case err := <-sent:
+			if err != nil {
+				return statusMsg{sType: STATUS_MESSAGE_FAILED, sRes: msg}
+			}
+			return statusMsg{sType: STATUS_MESSAGE_SEND}
+		case <-m.session.Done():
+			return nil
+		}
+	}
+}
+
+// Marks a message that could not be sent
+func (m *chatModel) failPending(msg *pb.Message) {
+	for i := range m.pending {
+		if m.pending[i].msg == msg {
+			m.pending[i].failed = true
+		}
+	}
+	m.msg = "Message not sent, /retry to send it again or /discard to drop it"
+	m.renderMessages()
+}
+
+// Drops the messages that could not be sent and returns how many there were
+func (m *chatModel) discardFailed() int {
+	kept := m.pending[:0]
+	for _, p := range m.pending {
+		if !p.failed {
+			kept = append(kept, p)
+		}
+	}
+	n := len(m.pending) - len(kept)
+	m.pending = kept
+	if n > 0 {
+		m.renderMessages()
+	}
+	return n
+}
+
+// Checks if any message could not be sent
+func (m chatModel) hasFailed() bool {
+	for _, p := range m.pending {
+		if p.failed {
+			return true
+		}
+	}
+	return false
+}
+
+// Sends the messages that could not be sent again, in order and under the
+// same ids so the server stores any that did reach it only once
+func (m *chatModel) retryPending() tea.Cmd {
+	// A failed send ends the stream, so the chat is opened again first
+	cmd, err := m.reopenChat()
+	if err != nil {
+		m.msg = errorTextStyle.Render("Cannot reconnect to the chat, /retry to try again: " + err.Error())
+		return nil
+	}
+
+	// Queued in order, so they are sent in order
+	cmds := []tea.Cmd{cmd}
+	for i := range m.pending {
+		if m.pending[i].failed {
+			m.pending[i].failed = false
+			cmds = append(cmds, m.sendPending(m.pending[i].msg))
+		}
+	}
+	m.renderMessages()
+	return tea.Batch(cmds...)
+}
+
+// Marks the message the server stored as acknowledged. Servers that assign
+// their own ids acknowledge messages in the order they were sent.
+func (m *chatModel) ackPending(ack *pb.Ack) {
+	i := m.pendingIndex(ack.MessageId)
+	if i == -1 {
+		i = m.oldestUnacked()
+	}
+	if i != -1 {
+		m.pending[i].acked = true
+		m.pending[i].msg.Id = ack.MessageId
+	}
+	m.renderMessages()
+}
+
+// Stops showing a sent message as pending now that it was streamed back.
+// Servers that assign their own ids and do not acknowledge messages stream
+// them back in the order they were sent.
+func (m *chatModel) resolvePending(msg *pb.Message) {
+	i := m.pendingIndex(msg.Id)
+	if i == -1 {
+		i = m.oldestUnacked()
+	}
+	if i != -1 {
+		m.pending = append(m.pending[:i], m.pending[i+1:]...)
+	}
+}
+
+// Returns the index of the pending message with id, or -1
+func (m chatModel) pendingIndex(id string) int {
+	for i, p := range m.pending {
+		if p.msg.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns the index of the oldest pending message waiting for an
+// acknowledgement, or -1
+func (m chatModel) oldestUnacked() int {
+	for i, p := range m.pending {
+		if !p.acked && !p.failed {
+			return i
+		}
+	}
+	return -1
+}
+
+// Merges received messages into msgs. A message received again replaces the
+// copy with the same id, and the messages stay ordered by when they were sent.
+func mergeMessages(msgs, received []*pb.Message) []*pb.Message {
+	index := map[string]int{}
+	merged := make([]*pb.Message, 0, len(msgs)+len(received))
+	for _, msg := range append(append([]*pb.Message{}, msgs...), received...) {
+		if i, ok := index[msg.Id]; ok && msg.Id != "" {
+			merged[i] = msg
+			continue
+		}
+		index[msg.Id] = len(merged)
+		merged = append(merged, msg)
+	}
+	// Ties are broken by id, which orders the ids made by sendMessage by
+	// when they were made
+	sort.SliceStable(merged, func(i, j int) bool {
+		a, b := merged[i].SentAt.AsTime(), merged[j].SentAt.AsTime()
+		if a.Equal(b) {
+			return merged[i].Id < merged[j].Id
+		}
+		return a.Before(b)
+	})
+	return merged
+}
+
+// Returns the messages of msgs sent after t
+func messagesAfter(msgs []*pb.Message, t time.Time) []*pb.Message {
+	var after []*pb.Message
+	for _, msg := range msgs {
+		if msg.SentAt.AsTime().After(t) {
+			after = append(after, msg)
+		}
+	}
+	return after
+}
+
+// Tells the others in the active chat that the user has read up to its
+// latest message, if the user is reading it
+func (m *chatModel) sendRead() tea.Cmd {
+	msgs := m.activeMessages()
+	if m.chatStream == nil || len(msgs) == 0 || !m.readingChat() {
+		return nil
+	}
+	id := msgs[len(msgs)-1].Id
+	if id == "" || id == m.readSent {
+		return nil
+	}
+	m.readSent = id
+	return m.send(&pb.MessageStream{ChatId: m.activeChat, Read: &pb.ReadMarker{
+		User:      m.user,
+		MessageId: id,
+		ReadAt:    timestamppb.Now(),
+	}})
+}
+
+// Checks if the user is reading the active chat: it is focused and they are
+// not away
+func (m chatModel) readingChat() bool {
+	focused := m.focusedPanel == MESSAGE_PANEL || m.focusedPanel == MESSSAGE_VIEW_PANEL
+	return focused && m.presenceState != pb.PresenceState_PRESENCE_STATE_AWAY
+}
+
+// Records how far a member of the active chat has read
+func (m *chatModel) receiveRead(read *pb.ReadMarker) {
+	i, chat, ok := m.activeChatItem()
+	if !ok || read.User == nil {
+		return
+	}
+	reads := map[string]string{}
+	for username, id := range chat.reads {
+		reads[username] = id
+	}
+	reads[read.User.Username] = read.MessageId
+	chat.reads = reads
+	m.chatList.SetItem(i, chat)
+	m.renderMessages()
+}
+
+// Returns the index of the latest message each member of chat other than the
+// user has read
+func (m chatModel) readPositions(chat chatItem) map[string]int {
+	index := map[string]int{}
+	for i, msg := range chat.messages {
+		if msg.Id != "" {
+			index[msg.Id] = i
+		}
+	}
+	positions := map[string]int{}
+	for username, id := range chat.reads {
+		if i, ok := index[id]; ok && username != m.user.Username {
+			positions[username] = i
+		}
+	}
+	return positions
+}
+
+// Renders whether the user's message at index i of chat was delivered or
+// read by all the other members
+func (m chatModel) formatReceipt(chat chatItem, positions map[string]int, i int) string {
+	for _, u := range chat.members {
+		if u.Username == m.user.Username {
+			continue
+		}
+		if pos, ok := positions[u.Username]; !ok || pos < i {
+			return suggestionStyle.Render("✓")
+		}
+	}
+	return successTextStyle.Render("✓✓")
+}
+
+// Renders the state of a message that has not been streamed back
+func formatPendingReceipt(p pendingMessage) string {
+	switch {
+	case p.failed:
+		return errorTextStyle.Render(fmt.Sprintf("%c", ICON_FAILED))
+	case p.acked:
+		return suggestionStyle.Render("✓")
+	}
+	return suggestionStyle.Render("◌")
+}
+
+// Renders who has read up to the message at index i and no further, e.g.
+// Read by alice, bob
+func (m chatModel) formatReaders(positions map[string]int, i int) string {
+	var readers []string
+	for username, pos := range positions {
+		if pos == i {
+			readers = append(readers, username)
+		}
+	}
+	if len(readers) == 0 {
+		return ""
+	}
+	sort.Strings(readers)
+
+	width := max(1, m.viewport.Width-1)
+	line := runewidth.Truncate("Read by "+strings.Join(readers, ", "), width, "…")
+	return lipgloss.PlaceHorizontal(width, lipgloss.Right, suggestionDescStyle.Render(line))
+}
@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestReadSentOnlyWhileReading(t *testing.T) {
+	tests := []struct {
+		name     string
+		focused  int
+		presence pb.PresenceState
+		want     bool
+	}{
+		{"input focused", MESSAGE_PANEL, pb.PresenceState_PRESENCE_STATE_ONLINE, true},
+		{"view focused", MESSSAGE_VIEW_PANEL, pb.PresenceState_PRESENCE_STATE_ONLINE, true},
+		{"without presence", MESSAGE_PANEL, pb.PresenceState_PRESENCE_STATE_UNSPECIFIED, true},
+		{"chat list focused", CHATS_PANEL, pb.PresenceState_PRESENCE_STATE_ONLINE, false},
+		{"details focused", DETAILS_PANEL, pb.PresenceState_PRESENCE_STATE_ONLINE, false},
+		{"away", MESSAGE_PANEL, pb.PresenceState_PRESENCE_STATE_AWAY, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestChatModel(chatItem{
+				id:       "a",
+				name:     "a",
+				chatType: pb.ChatType_CHAT_TYPE_GROUP,
+				messages: []*pb.Message{testMessage("1", "alice", time.Now())},
+			})
+			m.activeChat = "a"
+			m.setChatStream(&fakeChatStream{})
+			m.focusedPanel = tt.focused
+			m.presenceState = tt.presence
+
+			if got := m.sendRead() != nil; got != tt.want {
+				t.Errorf("read marker sent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadSentWhenChatFocused(t *testing.T) {
+	m := newTestChatModel(chatItem{
+		id:       "a",
+		name:     "a",
+		chatType: pb.ChatType_CHAT_TYPE_GROUP,
+		messages: []*pb.Message{testMessage("1", "alice", time.Now())},
+	})
+	m.activeChat = "a"
+	stream := &fakeChatStream{}
+	m.setChatStream(stream)
+	m.focusedPanel = CHATS_PANEL
+
+	model, _ := m.Update(statusMsg{sType: STATUS_MESSAGE_RECV, sRes: &pb.MessageStream{ChatId: "a", Message: testMessage("2", "alice", time.Now())}})
+	m = model.(chatModel)
+	if m.readSent != "" {
+		t.Fatalf("chat marked read up to %q while unfocused", m.readSent)
+	}
+
+	for i := 0; i < MAX_PANEL_NO && !m.readingChat(); i++ {
+		model, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+		m = model.(chatModel)
+	}
+	if m.readSent != "2" {
+		t.Errorf("chat marked read up to %q once focused, want 2", m.readSent)
+	}
+}
+
+func TestDiscardFailed(t *testing.T) {
+	m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.activeChat = "a"
+	m.setChatStream(&fakeChatStream{})
+	m.pending = []pendingMessage{
+		{msg: testMessage("1", "me", time.Now()), failed: true},
+		{msg: testMessage("2", "me", time.Now()), acked: true},
+		{msg: testMessage("3", "me", time.Now()), failed: true},
+	}
+
+	model, _ := m.runCommand("/discard")
+	m = model.(chatModel)
+	if len(m.pending) != 1 || m.pending[0].msg.Id != "2" {
+		t.Fatalf("pending after /discard = %v, want only 2", m.pending)
+	}
+
+	model, _ = m.runCommand("/discard")
+	m = model.(chatModel)
+	if m.commandErr == "" {
+		t.Error("/discard without failed messages did not report it")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error // of reopening the stream
+		sent    []string
+		failed  bool
+		message string
+	}{
+		{"reconnected", nil, []string{"1", "3"}, false, ""},
+		{"cannot reconnect", errors.New("unavailable"), nil, true, "Cannot reconnect"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &fakeChatStream{}
+			m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+			m.client = &fakeChatClient{stream: stream, err: tt.err}
+			m.activeChat = "a"
+			m.setChatStream(&fakeChatStream{err: errors.New("closed")})
+			m.pending = []pendingMessage{
+				{msg: testMessage("1", "me", time.Now()), failed: true},
+				{msg: testMessage("2", "me", time.Now()), acked: true},
+				{msg: testMessage("3", "me", time.Now()), failed: true},
+			}
+
+			model, cmd := m.runCommand("/retry")
+			m = model.(chatModel)
+			if tt.err == nil && cmd == nil {
+				t.Fatal("nothing was sent")
+			}
+			if cmd != nil {
+				for _, msg := range runCmd(cmd) {
+					if _, ok := msg.(tea.QuitMsg); ok {
+						t.Fatal("retrying quit")
+					}
+				}
+			}
+
+			var sent []string
+			for _, s := range stream.sent {
+				sent = append(sent, s.Message.Id)
+			}
+			if !reflect.DeepEqual(sent, tt.sent) {
+				t.Errorf("sent %q, want %q", sent, tt.sent)
+			}
+			if m.pending[0].failed != tt.failed || m.pending[2].failed != tt.failed {
+				t.Errorf("messages failed = %v, want %v", m.pending[0].failed, tt.failed)
+			}
+			if !strings.Contains(m.msg, tt.message) {
+				t.Errorf("msg = %q, want it to contain %q", m.msg, tt.message)
+			}
+		})
+	}
+}
+
+func TestMergeMessages(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	edited := testMessage("2", "bob", at.Add(time.Minute))
+	edited.Content = "edited"
+
+	tests := []struct {
+		name     string
+		msgs     []*pb.Message
+		received []*pb.Message
+		want     []string // ids and content
+	}{
+		{"nothing received", []*pb.Message{testMessage("1", "alice", at)}, nil, []string{"1:1"}},
+		{"appended", []*pb.Message{testMessage("1", "alice", at)}, []*pb.Message{testMessage("2", "bob", at.Add(time.Minute))}, []string{"1:1", "2:2"}},
+		{"received again", []*pb.Message{testMessage("1", "alice", at), testMessage("2", "bob", at.Add(time.Minute))}, []*pb.Message{edited}, []string{"1:1", "2:edited"}},
+		{"out of order", []*pb.Message{testMessage("2", "bob", at.Add(time.Minute))}, []*pb.Message{testMessage("1", "alice", at)}, []string{"1:1", "2:2"}},
+		{"same time by id", []*pb.Message{testMessage("b", "bob", at)}, []*pb.Message{testMessage("a", "alice", at)}, []string{"a:a", "b:b"}},
+		{"without ids", []*pb.Message{testMessage("", "alice", at)}, []*pb.Message{testMessage("", "bob", at.Add(time.Minute))}, []string{":", ":"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, msg := range mergeMessages(tt.msgs, tt.received) {
+				got = append(got, msg.Id+":"+msg.Content)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeMessages = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessagesAfter(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	msgs := []*pb.Message{
+		testMessage("1", "alice", at),
+		testMessage("2", "bob", at.Add(time.Minute)),
+		testMessage("3", "alice", at.Add(2*time.Minute)),
+	}
+	tests := []struct {
+		name string
+		t    time.Time
+		want []string
+	}{
+		{"before all", at.Add(-time.Minute), []string{"1", "2", "3"}},
+		{"at a message", at.Add(time.Minute), []string{"3"}},
+		{"after all", at.Add(time.Hour), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, msg := range messagesAfter(msgs, tt.t) {
+				got = append(got, msg.Id)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("messagesAfter = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newSearchInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "Search messages"
+	ti.Prompt = "/"
+	return ti
+}
+
+func (m *chatModel) openSearch() tea.Cmd {
+	m.searching = true
+	m.searchInput.Reset()
+	return m.searchInput.Focus()
+}
+
+func (m chatModel) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch {
+	case key.Matches(msg, m.keys.Enter):
+		m.searching = false
+		m.searchInput.Blur()
+		if q := strings.TrimSpace(m.searchInput.Value()); q != "" {
+			m.searchQuery = q
+			m.findMessage(1)
+		}
+		return m, m.drawOverlays()
+	case key.Matches(msg, m.keys.Cancel):
+		m.searching = false
+		m.searchInput.Blur()
+	default:
+		m.searchInput, cmd = m.searchInput.Update(msg)
+	}
+
+	return m, cmd
+}
+
+// Selects the next message containing the search query, after the selected
+// message if dir is 1 or before it if dir is -1. The search wraps around the
+// chat.
+func (m *chatModel) findMessage(dir int) {
+	if m.searchQuery == "" {
+		return
+	}
+	msgs := m.activeMessages()
+	query := strings.ToLower(m.searchQuery)
+	for i := 1; i <= len(msgs); i++ {
+		j := ((m.selectedMsg+dir*i)%len(msgs) + len(msgs)) % len(msgs)
+		if strings.Contains(strings.ToLower(msgs[j].Content), query) {
+			m.selectedMsg = j
+			m.showReactors = false
+			m.msg = ""
+			m.renderMessages()
+			return
+		}
+	}
+	m.msg = "Pattern not found: " + m.searchQuery
+}
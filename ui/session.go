@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// A message reported by a command of a chat session. Messages of a session
+// that was logged out of are dropped, so they never reach the login screen.
+type sessionMsg struct {
+	session context.Context
+	msg     tea.Msg
+}
+
+// Tags the messages reported by cmd, and by the commands it batches, with
+// the session. The commands of a sequence are not reached, so they are
+// tagged before they are sequenced.
+func inSession(session context.Context, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case errMsg, statusMsg:
+			return sessionMsg{session: session, msg: msg}
+		case tea.BatchMsg:
+			for i, c := range msg {
+				msg[i] = inSession(session, c)
+			}
+			return msg
+		default:
+			return msg
+		}
+	}
+}
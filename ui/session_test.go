@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Runs cmd and the commands it batches or sequences, returning their messages
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	var msgs []tea.Msg
+	switch msg := cmd().(type) {
+	case nil:
+	case tea.BatchMsg:
+		for _, c := range msg {
+			msgs = append(msgs, runCmd(c)...)
+		}
+	default:
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func TestInSessionTagsMessages(t *testing.T) {
+	session := context.Background()
+	status := func() tea.Msg { return statusMsg{sType: STATUS_MESSAGE_SEND} }
+	quit := tea.Quit
+
+	tests := []struct {
+		name   string
+		cmd    tea.Cmd
+		tagged int
+	}{
+		{"status", status, 1},
+		{"error", func() tea.Msg { return errMsg{context.Canceled} }, 1},
+		{"other messages", quit, 0},
+		{"batch", tea.Batch(status, status, quit), 2},
+		{"nested batch", tea.Batch(status, tea.Batch(status, status)), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tagged := 0
+			for _, msg := range runCmd(inSession(session, tt.cmd)) {
+				if s, ok := msg.(sessionMsg); ok && s.session == session {
+					tagged++
+				}
+			}
+			if tagged != tt.tagged {
+				t.Errorf("%d messages tagged, want %d", tagged, tt.tagged)
+			}
+		})
+	}
+}
+
+func TestLogoutEndsSession(t *testing.T) {
+	m := newTestChatModel()
+	model, _ := m.logout()
+	login, ok := model.(loginModel)
+	if !ok {
+		t.Fatalf("logging out went to %T", model)
+	}
+	if m.session.Err() == nil {
+		t.Error("the session was not ended")
+	}
+
+	// A request of the session failing once it is cancelled
+	_, cmd := login.Update(sessionMsg{session: m.session, msg: errMsg{context.Canceled}})
+	if cmd != nil {
+		if _, quit := cmd().(tea.QuitMsg); quit {
+			t.Error("the login screen quit on an error of the ended session")
+		}
+	}
+}
+
+func TestEndedSessionIsDropped(t *testing.T) {
+	m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.activeChat = "a"
+	ended, end := context.WithCancel(context.Background())
+	end()
+
+	recv := statusMsg{sType: STATUS_MESSAGE_RECV, sRes: &pb.MessageStream{ChatId: "a", Message: testMessage("1", "alice", time.Now())}}
+	model, _ := m.Update(sessionMsg{session: ended, msg: recv})
+	if len(model.(chatModel).activeMessages()) != 0 {
+		t.Error("a message of an ended session was handled")
+	}
+	model, _ = m.Update(sessionMsg{session: m.session, msg: recv})
+	if len(model.(chatModel).activeMessages()) != 1 {
+		t.Error("a message of the session was dropped")
+	}
+}
+
+func TestLogoutStopsWaiting(t *testing.T) {
+	m := newTestChatModel()
+	m.msgChan = make(chan *pb.MessageStream)
+	wait := m.wait()
+	done := make(chan tea.Msg)
+	go func() { done <- wait() }()
+
+	m.endSession()
+	select {
+	case msg := <-done:
+		if msg != nil {
+			t.Errorf("wait reported %v after the session ended", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wait did not return after the session ended")
+	}
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Levels of each channel in the colour cube used as the sixel palette
+const sixelLevels = 6
+
+// Encodes img as sixel graphics. Colours are mapped to a 6x6x6 colour cube
+// and transparent pixels are left undrawn.
+func encodeSixel(img image.Image) string {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	colors := sixelLevels * sixelLevels * sixelLevels
+
+	// Palette index of every pixel, -1 for transparent pixels
+	idx := make([]int, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			if c.A < 128 {
+				idx[y*w+x] = -1
+				continue
+			}
+			level := func(v uint8) int { return (int(v)*(sixelLevels-1) + 127) / 255 }
+			idx[y*w+x] = level(c.R)*sixelLevels*sixelLevels + level(c.G)*sixelLevels + level(c.B)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1bPq\"1;1;%d;%d", w, h)
+	for i := 0; i < colors; i++ {
+		percent := func(level int) int { return level * 100 / (sixelLevels - 1) }
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, percent(i/(sixelLevels*sixelLevels)), percent(i/sixelLevels%sixelLevels), percent(i%sixelLevels))
+	}
+
+	// Each band is 6 pixels high and drawn one colour at a time
+	for band := 0; band < h; band += 6 {
+		used := make([]bool, colors)
+		for y := band; y < min(band+6, h); y++ {
+			for x := 0; x < w; x++ {
+				if i := idx[y*w+x]; i >= 0 {
+					used[i] = true
+				}
+			}
+		}
+
+		first := true
+		for c := 0; c < colors; c++ {
+			if !used[c] {
+				continue
+			}
+			if !first {
+				b.WriteByte('$') // return to the start of the band
+			}
+			first = false
+			fmt.Fprintf(&b, "#%d", c)
+
+			var prev byte
+			run := 0
+			for x := 0; x < w; x++ {
+				var bits byte
+				for i := 0; i < 6 && band+i < h; i++ {
+					if idx[(band+i)*w+x] == c {
+						bits |= 1 << i
+					}
+				}
+				if ch := 63 + bits; ch == prev {
+					run++
+				} else {
+					writeSixelRun(&b, prev, run)
+					prev, run = ch, 1
+				}
+			}
+			writeSixelRun(&b, prev, run)
+		}
+		b.WriteByte('-')
+	}
+	b.WriteString("\x1b\\")
+
+	return b.String()
+}
+
+func writeSixelRun(b *strings.Builder, ch byte, run int) {
+	switch {
+	case run == 0:
+	case run > 3:
+		fmt.Fprintf(b, "!%d%c", run, ch)
+	default:
+		b.WriteString(strings.Repeat(string(ch), run))
+	}
+}
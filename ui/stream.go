@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/Ayobami0/cli-chat/pb"
+)
+
+// Sends on the stream of the open chat. A gRPC stream must not be sent on
+// from more than one goroutine at a time, so the messages, read markers and
+// typing events are queued and sent by one goroutine in the order they were
+// queued.
+type streamWriter struct {
+	mu     sync.Mutex
+	queue  []streamSend
+	closed bool
+	ready  chan struct{} // holds a value while the queue is not empty
+}
+
+type streamSend struct {
+	msg  *pb.MessageStream // nil closes the stream
+	sent chan error
+}
+
+// Starts sending on stream until it is closed or the session ends
+func newStreamWriter(session context.Context, stream pb.ChatService_ChatStreamClient) *streamWriter {
+	w := &streamWriter{ready: make(chan struct{}, 1)}
+	go w.run(session, stream)
+	return w
+}
+
+// Queues msg and returns the channel the result of sending it is sent on
+func (w *streamWriter) send(msg *pb.MessageStream) <-chan error {
+	sent := make(chan error, 1)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		sent <- io.ErrClosedPipe
+		return sent
+	}
+	w.push(streamSend{msg: msg, sent: sent})
+	return sent
+}
+
+// Closes the stream once what was queued before is sent
+func (w *streamWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+	w.push(streamSend{sent: make(chan error, 1)})
+}
+
+func (w *streamWriter) push(s streamSend) {
+	w.queue = append(w.queue, s)
+	select {
+	case w.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (w *streamWriter) run(session context.Context, stream pb.ChatService_ChatStreamClient) {
+	for {
+		select {
+		case <-w.ready:
+		case <-session.Done():
+			return
+		}
+		w.mu.Lock()
+		queue := w.queue
+		w.queue = nil
+		w.mu.Unlock()
+
+		for _, s := range queue {
+			if s.msg == nil {
+				s.sent <- stream.CloseSend()
+				return
+			}
+			s.sent <- stream.Send(s.msg)
+		}
+	}
+}
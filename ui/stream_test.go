@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestStreamSendsInOrderFromOneGoroutine(t *testing.T) {
+	m := newTestChatModel(chatItem{
+		id:       "a",
+		name:     "a",
+		chatType: pb.ChatType_CHAT_TYPE_GROUP,
+		messages: []*pb.Message{testMessage("1", "alice", time.Now())},
+	})
+	m.activeChat = "a"
+	stream := &fakeChatStream{}
+	m.setChatStream(stream)
+	m.focusedPanel = MESSAGE_PANEL
+
+	m.input.SetValue("hi")
+	cmds := []tea.Cmd{m.sendTyping(), m.sendMessage("one"), m.sendRead(), m.sendMessage("two")}
+
+	// bubbletea runs each command in its own goroutine
+	var wg sync.WaitGroup
+	for _, cmd := range cmds {
+		if cmd == nil {
+			t.Fatal("nothing was sent")
+		}
+		wg.Add(1)
+		go func(cmd tea.Cmd) {
+			defer wg.Done()
+			if msg, ok := cmd().(statusMsg); !ok || msg.sType != STATUS_MESSAGE_SEND {
+				t.Errorf("send returned %v", msg)
+			}
+		}(cmd)
+	}
+	wg.Wait()
+
+	if stream.concurrent.Load() {
+		t.Error("the stream was sent on from more than one goroutine at a time")
+	}
+	var got []string
+	for _, s := range stream.sent {
+		switch {
+		case s.Typing != nil:
+			got = append(got, "typing")
+		case s.Read != nil:
+			got = append(got, "read")
+		case s.Message != nil:
+			got = append(got, s.Message.Content)
+		}
+	}
+	want := []string{"typing", "one", "read", "two"}
+	if len(got) != len(want) {
+		t.Fatalf("sent %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("sent %q, want %q", got, want)
+		}
+	}
+}
+
+func TestStreamClosedAfterQueuedSends(t *testing.T) {
+	m := newTestChatModel(chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP})
+	m.activeChat = "a"
+	stream := &fakeChatStream{}
+	m.setChatStream(stream)
+
+	cmd := m.sendMessage("last")
+	m.chatWriter.close()
+	if msg, ok := cmd().(statusMsg); !ok || msg.sType != STATUS_MESSAGE_SEND {
+		t.Fatalf("a message queued before closing was not sent: %v", msg)
+	}
+	if msg, ok := m.sendMessage("late")().(statusMsg); !ok || msg.sType != STATUS_MESSAGE_FAILED {
+		t.Errorf("a message queued after closing was not failed: %v", msg)
+	}
+}
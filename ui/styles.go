@@ -8,22 +8,84 @@ import (
 var defaultStyle = lipgloss.NewStyle()
 
 // COLORS
-var successColor = lipgloss.Color("#007d69")
-var errorColor = lipgloss.Color("#ff3333")
-var unfocusedBorderColor = lipgloss.Color("#353635")
-var focusedBorderColor = lipgloss.Color("#e8e8e8")
-var notificationForegroundColor = lipgloss.Color("4")
-var senderColor = lipgloss.Color("10")
+var successColor lipgloss.Color
+var errorColor lipgloss.Color
+var unfocusedBorderColor lipgloss.Color
+var focusedBorderColor lipgloss.Color
+var notificationForegroundColor lipgloss.Color
+var senderColor lipgloss.Color
+var mutedColor lipgloss.Color
+var userColors []lipgloss.Color
 
 // BORDERS
-var unfocusedBorderStyle = defaultStyle.Copy().BorderStyle(lipgloss.NormalBorder()).BorderForeground(unfocusedBorderColor)
-var focusedBorderStyle = defaultStyle.Copy().BorderStyle(lipgloss.NormalBorder()).BorderForeground(focusedBorderColor)
+var unfocusedBorderStyle lipgloss.Style
+var focusedBorderStyle lipgloss.Style
+var selectedMessageStyle lipgloss.Style
 
 // TEXT
-var notificationTextStyle = defaultStyle.Copy().Foreground(notificationForegroundColor)
-var senderTextStyle = defaultStyle.Copy().Foreground(senderColor)
-var errorTextStyle = defaultStyle.Copy().Foreground(errorColor)
-var successTextStyle = defaultStyle.Copy().Foreground(successColor)
+var notificationTextStyle lipgloss.Style
+var senderTextStyle lipgloss.Style
+var errorTextStyle lipgloss.Style
+var successTextStyle lipgloss.Style
+var reactionTextStyle lipgloss.Style
+var selfReactionTextStyle lipgloss.Style
+var attachmentTextStyle lipgloss.Style
+var modeStyle lipgloss.Style
+var timestampTextStyle lipgloss.Style
+var mentionTextStyle lipgloss.Style
+
+// SUGGESTIONS
+var suggestionStyle lipgloss.Style
+var selectedSuggestionStyle lipgloss.Style
+var suggestionDescStyle lipgloss.Style
 
 // HELP
-var helpStyle = help.Styles{ShortSeparator: defaultStyle.Copy().Foreground(senderColor)}
+var helpStyle help.Styles
+
+// MARKDOWN
+var markdownTheme string
+
+func init() {
+	setStyles(themes["dark"])
+}
+
+func setStyles(t Theme) {
+	successColor = lipgloss.Color(t.Success)
+	errorColor = lipgloss.Color(t.Error)
+	unfocusedBorderColor = lipgloss.Color(t.Border)
+	focusedBorderColor = lipgloss.Color(t.FocusedBorder)
+	notificationForegroundColor = lipgloss.Color(t.Notification)
+	senderColor = lipgloss.Color(t.Sender)
+	mutedColor = lipgloss.Color(t.Muted)
+	userColors = nil
+	for _, c := range t.Users {
+		userColors = append(userColors, lipgloss.Color(c))
+	}
+
+	unfocusedBorderStyle = defaultStyle.Copy().BorderStyle(lipgloss.NormalBorder()).BorderForeground(unfocusedBorderColor)
+	focusedBorderStyle = defaultStyle.Copy().BorderStyle(lipgloss.NormalBorder()).BorderForeground(focusedBorderColor)
+	if noColor() {
+		// The focused panel cannot be told apart by colour
+		focusedBorderStyle = focusedBorderStyle.BorderStyle(lipgloss.ThickBorder())
+	}
+	selectedMessageStyle = defaultStyle.Copy().BorderStyle(lipgloss.ThickBorder()).BorderLeft(true).BorderForeground(focusedBorderColor)
+
+	notificationTextStyle = defaultStyle.Copy().Foreground(notificationForegroundColor)
+	senderTextStyle = defaultStyle.Copy().Foreground(senderColor)
+	errorTextStyle = defaultStyle.Copy().Foreground(errorColor)
+	successTextStyle = defaultStyle.Copy().Foreground(successColor)
+	reactionTextStyle = defaultStyle.Copy().Foreground(mutedColor)
+	selfReactionTextStyle = defaultStyle.Copy().Foreground(senderColor)
+	attachmentTextStyle = defaultStyle.Copy().Foreground(notificationForegroundColor).Underline(true)
+	modeStyle = defaultStyle.Copy().Foreground(senderColor).Bold(true)
+	timestampTextStyle = defaultStyle.Copy().Foreground(mutedColor)
+	mentionTextStyle = defaultStyle.Copy().Foreground(notificationForegroundColor).Bold(true)
+
+	suggestionStyle = defaultStyle.Copy().Foreground(mutedColor)
+	selectedSuggestionStyle = defaultStyle.Copy().Foreground(focusedBorderColor)
+	suggestionDescStyle = defaultStyle.Copy().Foreground(mutedColor).Italic(true)
+
+	helpStyle = help.Styles{ShortSeparator: defaultStyle.Copy().Foreground(senderColor)}
+
+	markdownTheme = t.Markdown
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Stands in for the content of a message while its template is laid out
+const contentPlaceholder = "\uE000"
+
+// Built-in message templates
+var messageTemplates = map[string]string{
+	"default": `{{with .Time}}{{muted .}} {{end}}{{if .Grouped}}{{pad .Sender}}  {{else}}{{.Colour .Sender}}: {{end}}{{.Content}}`,
+	"irc":     `{{with .Time}}{{muted (printf "[%s]" .)}} {{end}}{{.Colour (printf "<%s>" .Sender)}} {{.Content}}`,
+	"compact": `{{if .Grouped}}{{pad .Sender}}  {{else}}{{.Colour .Sender}}: {{end}}{{.Content}}`,
+	"block": `{{if not .Grouped}}{{bold (.Colour .Sender)}} {{muted .Time}}
+{{end}}  {{.Content}}`,
+}
+
+var templateFuncs = template.FuncMap{
+	"muted": func(s string) string { return timestampTextStyle.Render(s) },
+	"bold":  func(s string) string { return defaultStyle.Copy().Bold(true).Render(s) },
+	// Spaces as wide as s, to line up grouped messages
+	"pad": func(s string) string { return strings.Repeat(" ", lipgloss.Width(s)) },
+}
+
+// What message templates are executed with
+type messageData struct {
+	Sender  string // username of the sender, or Me
+	Self    bool
+	Content string
+	Time    string // formatted as configured, empty if timestamps are off
+	SentAt  time.Time
+	Type    string // message or attachment
+	Grouped bool   // continues the messages of the previous sender
+	Chat    chatData
+	style   lipgloss.Style
+}
+
+type chatData struct {
+	Name    string
+	Direct  bool
+	Members []string
+}
+
+// Renders s in the colour of the sender
+func (d messageData) Colour(s string) string {
+	return d.style.Render(s)
+}
+
+// Parses the template called name, or name itself as a template. The
+// template is tried on a sample message so mistakes show at startup.
+func parseMessageTemplate(name string) (*template.Template, error) {
+	text, ok := messageTemplates[name]
+	if !ok {
+		text = name
+	}
+	t, err := template.New("message").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	sample := messageData{Sender: "Me", Self: true, Content: contentPlaceholder, Time: "12:00", SentAt: time.Now(), Type: "message"}
+	if err := t.Execute(&b, sample); err != nil {
+		return nil, err
+	}
+	if strings.Count(b.String(), contentPlaceholder) != 1 {
+		return nil, errors.New("the template must show {{.Content}} once")
+	}
+	return t, nil
+}
+
+// The output of a message template, split around the content
+type messageLayout struct {
+	above  []string // lines before the line of the content
+	prefix string   // text before the content on its line
+	suffix string   // text after the content on its line
+	below  []string // lines after the line of the content
+}
+
+// Returns what message templates are told about a chat
+func newChatData(chat chatItem) chatData {
+	data := chatData{Name: chat.displayName(), Direct: chat.chatType == pb.ChatType_CHAT_TYPE_DIRECT}
+	for _, u := range chat.members {
+		data.Members = append(data.Members, u.Username)
+	}
+	return data
+}
+
+func (m chatModel) layoutMessage(msg *pb.Message, grouped bool, chat chatData) (messageLayout, error) {
+	data := messageData{
+		Sender:  msg.Sender.GetUsername(),
+		Content: contentPlaceholder,
+		Time:    m.formatTimestamp(msg.SentAt.AsTime()),
+		SentAt:  msg.SentAt.AsTime(),
+		Type:    "message",
+		Grouped: grouped,
+		Chat:    chat,
+		style:   userStyle(msg.Sender.GetUsername()),
+	}
+	if data.Sender == m.user.Username {
+		data.Sender, data.Self, data.style = "Me", true, senderTextStyle
+	}
+	if msg.Type == pb.Message_MESSAGE_TYPE_ATTACHMENT {
+		data.Type = "attachment"
+	}
+
+	// Messages are still shown if the template fails on one
+	fallback := messageLayout{prefix: data.Colour(data.Sender) + ": "}
+	var b strings.Builder
+	if err := m.cfg.messageTemplate.Execute(&b, data); err != nil {
+		return fallback, err
+	}
+	if strings.Count(b.String(), contentPlaceholder) != 1 {
+		return fallback, errors.New("the template must show {{.Content}} once")
+	}
+
+	var l messageLayout
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		if before, after, ok := strings.Cut(line, contentPlaceholder); ok {
+			l.above, l.prefix, l.suffix, l.below = lines[:i], before, after, lines[i+1:]
+			break
+		}
+	}
+	return l, nil
+}
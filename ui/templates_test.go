@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+)
+
+func TestParseMessageTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr bool
+	}{
+		{"built-in", "default", false},
+		{"irc", "irc", false},
+		{"custom", "{{.Sender}}> {{.Content}}", false},
+		{"multiline", "{{.Sender}}\n{{.Content}}\n--", false},
+		{"no content", "{{.Sender}}", true},
+		{"content twice", "{{.Content}} {{.Content}}", true},
+		{"syntax error", "{{.Sender", true},
+		{"unknown field", "{{.Nope}} {{.Content}}", true},
+		{"unknown function", "{{shout .Sender}} {{.Content}}", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseMessageTemplate(tt.tmpl); (err != nil) != tt.wantErr {
+				t.Errorf("parseMessageTemplate(%q) = %v, want error %v", tt.tmpl, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplateFailureIsReportedOnce(t *testing.T) {
+	m := newTestChatModel(chatItem{
+		id:       "a",
+		chatType: pb.ChatType_CHAT_TYPE_DIRECT,
+		partner:  "alice",
+		members:  []*pb.User{{Username: "me"}, {Username: "alice"}},
+	})
+	m.activeChat = "a"
+	// Passes on the sample message, fails in direct chats
+	tmpl, err := parseMessageTemplate("{{if .Chat.Direct}}{{index .Chat.Members 9}}{{end}}{{.Content}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.cfg.messageTemplate = tmpl
+
+	i, chat, _ := m.activeChatItem()
+	chat.messages = []*pb.Message{testMessage("hello", "alice", time.Now())}
+	m.chatList.SetItem(i, chat)
+	m.renderMessages()
+
+	if !strings.Contains(m.msg, "Message template failed") {
+		t.Fatalf("the failure was not reported, msg %q", m.msg)
+	}
+	if view := m.viewport.View(); !strings.Contains(view, "hello") {
+		t.Errorf("the message was not shown:\n%s", view)
+	}
+	m.msg = ""
+	m.renderMessages()
+	if m.msg != "" {
+		t.Errorf("the failure was reported again: %q", m.msg)
+	}
+}
+
+func TestRenderWithoutOpenChat(t *testing.T) {
+	m := newTestChatModel(chatItem{id: "a", chatType: pb.ChatType_CHAT_TYPE_DIRECT})
+	m.renderMessages()
+	if m.templateFailed {
+		t.Errorf("the template failed with no chat open: %q", m.msg)
+	}
+}
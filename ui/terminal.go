@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
+)
+
+// The terminal the program renders to. bubbletea writes each frame in a
+// single write, so the escape sequences written outside of the view, such as
+// images and the clipboard, go through it too and land between frames.
+type terminal struct {
+	*os.File
+	mu sync.Mutex
+}
+
+func (t *terminal) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.File.Write(b)
+}
+
+var stdout = &terminal{File: os.Stdout}
+
+// Writes escape sequences bubbletea does not know about to the terminal.
+// They would otherwise be mangled when the view is measured.
+func writeTerminal(s string) tea.Cmd {
+	if s == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		io.WriteString(stdout, s)
+		return nil
+	}
+}
+
+// Runs the program starting with model until it quits
+func Run(model tea.Model) error {
+	p := tea.NewProgram(model, tea.WithOutput(stdout))
+
+	// bubbletea only follows the size of an output that is a file
+	done := make(chan struct{})
+	defer close(done)
+	go followSize(p, done)
+
+	_, err := p.Run()
+	return err
+}
+
+// Sends the size of the terminal to p
+func sendSize(p *tea.Program) {
+	w, h, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return
+	}
+	p.Send(tea.WindowSizeMsg{Width: w, Height: h})
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package ui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Sends the size of the terminal to p. Resizes are not reported outside of
+// unix.
+func followSize(p *tea.Program, done <-chan struct{}) {
+	sendSize(p)
+}
@@ -0,0 +1,28 @@
+//go:build unix
+
+package ui
+
+import (
+	"os"
+	"os/signal"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/sys/unix"
+)
+
+// Sends the size of the terminal to p now and whenever it is resized, until
+// done is closed
+func followSize(p *tea.Program, done <-chan struct{}) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, unix.SIGWINCH)
+	defer signal.Stop(sig)
+
+	for {
+		sendSize(p)
+		select {
+		case <-sig:
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Colours of the interface. Colours are hex values i.e. #ff3333 or ANSI
+// colour numbers i.e. 4. Colours left out fall back to the dark or light
+// theme, whichever suits the terminal background.
+type Theme struct {
+	Border        string `json:"border"`
+	FocusedBorder string `json:"focused_border"`
+	Sender        string `json:"sender"`
+	Notification  string `json:"notification"`
+	Error         string `json:"error"`
+	Success       string `json:"success"`
+	Muted         string `json:"muted"`
+	// Colours senders are told apart by
+	Users []string `json:"users"`
+	// Style messages are rendered in. Either dark or light
+	Markdown string `json:"markdown"`
+}
+
+// Built-in themes
+var themes = map[string]Theme{
+	"dark": {
+		Border:        "#353635",
+		FocusedBorder: "#e8e8e8",
+		Sender:        "10",
+		Notification:  "4",
+		Error:         "#ff3333",
+		Success:       "#007d69",
+		Muted:         "8",
+		Users:         []string{"#ff8787", "#ffaf5f", "#d7d75f", "#87d787", "#5fd7d7", "#87afff", "#d787ff", "#ff87d7"},
+		Markdown:      "dark",
+	},
+	"light": {
+		Border:        "#c4c4c4",
+		FocusedBorder: "#1c1c1c",
+		Sender:        "#00703c",
+		Notification:  "#0050a0",
+		Error:         "#c00000",
+		Success:       "#006b5a",
+		Muted:         "#6c6c6c",
+		Users:         []string{"#af0000", "#af5f00", "#878700", "#008700", "#008787", "#005fd7", "#8700af", "#af0087"},
+		Markdown:      "light",
+	},
+	// For dark backgrounds
+	"high-contrast": {
+		Border:        "#a8a8a8",
+		FocusedBorder: "#ffffff",
+		Sender:        "#ffff00",
+		Notification:  "#00ffff",
+		Error:         "#ff5f5f",
+		Success:       "#5fff5f",
+		Muted:         "#d0d0d0",
+		Users:         []string{"#ff5f5f", "#ffd700", "#5fff5f", "#5fffff", "#87afff", "#ff87ff"},
+		Markdown:      "dark",
+	},
+	// Okabe-Ito colours, which stay distinct with any colour blindness and
+	// read on dark and light backgrounds. Messages follow the terminal
+	// background.
+	"colorblind": {
+		Border:        "#999999",
+		FocusedBorder: "#0072b2",
+		Sender:        "#56b4e9",
+		Notification:  "#e69f00",
+		Error:         "#d55e00",
+		Success:       "#009e73",
+		Muted:         "#999999",
+		Users:         []string{"#e69f00", "#56b4e9", "#009e73", "#f0e442", "#0072b2", "#d55e00", "#cc79a7"},
+	},
+}
+
+// Checks if colours are disabled with NO_COLOR (https://no-color.org)
+func noColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// Returns the built-in theme called name, or reads the theme file name
+// refers to. Theme files are looked up in the themes directory next to the
+// config file unless name is a path.
+func loadTheme(name, configDir string) (Theme, error) {
+	if name == "auto" {
+		return Theme{}, nil
+	}
+	if t, ok := themes[name]; ok {
+		return t, nil
+	}
+
+	path := name
+	if !strings.ContainsRune(name, filepath.Separator) && filepath.Ext(name) != ".json" {
+		path = filepath.Join(configDir, "themes", name+".json")
+	} else if strings.HasPrefix(name, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, name[2:])
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, fmt.Errorf("unknown theme %q: %w", name, err)
+	}
+	var t Theme
+	if err := json.Unmarshal(b, &t); err != nil {
+		return Theme{}, fmt.Errorf("invalid theme %s: %w", path, err)
+	}
+	switch t.Markdown {
+	case "", "dark", "light":
+	default:
+		return Theme{}, fmt.Errorf("invalid theme %s: unknown markdown %q", path, t.Markdown)
+	}
+	for _, c := range []string{t.Border, t.FocusedBorder, t.Sender, t.Notification, t.Error, t.Success, t.Muted} {
+		if c != "" && !validColour(c) {
+			return Theme{}, fmt.Errorf("invalid theme %s: invalid colour %q", path, c)
+		}
+	}
+	for _, c := range t.Users {
+		if !validColour(c) {
+			return Theme{}, fmt.Errorf("invalid theme %s: invalid user colour %q", path, c)
+		}
+	}
+	return t, nil
+}
+
+// Checks if c is a hex colour i.e. #f33 or #ff3333, or an ANSI colour number
+// from 0 to 255
+func validColour(c string) bool {
+	if hex, ok := strings.CutPrefix(c, "#"); ok {
+		if len(hex) != 3 && len(hex) != 6 {
+			return false
+		}
+		_, err := strconv.ParseUint(hex, 16, 32)
+		return err == nil
+	}
+	n, err := strconv.Atoi(c)
+	return err == nil && n >= 0 && n <= 255 && c == strconv.Itoa(n)
+}
+
+// Fills the colours left out of t from base
+func (t Theme) withDefaults(base Theme) Theme {
+	for _, c := range []struct{ v, base *string }{
+		{&t.Border, &base.Border},
+		{&t.FocusedBorder, &base.FocusedBorder},
+		{&t.Sender, &base.Sender},
+		{&t.Notification, &base.Notification},
+		{&t.Error, &base.Error},
+		{&t.Success, &base.Success},
+		{&t.Muted, &base.Muted},
+		{&t.Markdown, &base.Markdown},
+	} {
+		if *c.v == "" {
+			*c.v = *c.base
+		}
+	}
+	if len(t.Users) == 0 {
+		t.Users = base.Users
+	}
+	return t
+}
+
+// Sets the styles from the configured theme
+func applyTheme(t Theme) {
+	base := themes["dark"]
+	if !lipgloss.HasDarkBackground() {
+		base = themes["light"]
+	}
+	setStyles(t.withDefaults(base))
+}
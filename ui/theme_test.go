@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		theme   string
+		wantErr bool
+	}{
+		{"empty", `{}`, false},
+		{"hex colours", `{"border": "#333", "sender": "#FF3333", "users": ["#abcdef"]}`, false},
+		{"ansi colours", `{"border": "0", "sender": "255"}`, false},
+		{"named colour", `{"border": "red"}`, true},
+		{"short hex", `{"border": "#33"}`, true},
+		{"bad hex", `{"error": "#gg0000"}`, true},
+		{"ansi out of range", `{"muted": "256"}`, true},
+		{"negative ansi", `{"muted": "-1"}`, true},
+		{"padded ansi", `{"muted": "08"}`, true},
+		{"bad user colour", `{"users": ["#ff0000", ""]}`, true},
+		{"unknown markdown", `{"markdown": "blue"}`, true},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "theme.json")
+			if err := os.WriteFile(path, []byte(tt.theme), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := loadTheme(path, dir); (err != nil) != tt.wantErr {
+				t.Errorf("loadTheme(%s) = %v, want error %v", tt.theme, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuiltinThemesAreComplete(t *testing.T) {
+	for name, theme := range themes {
+		for _, c := range []string{theme.Border, theme.FocusedBorder, theme.Sender, theme.Notification, theme.Error, theme.Success, theme.Muted} {
+			if !validColour(c) {
+				t.Errorf("theme %s has invalid colour %q", name, c)
+			}
+		}
+		if len(theme.Users) == 0 {
+			t.Errorf("theme %s has no user colours", name)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	// Least time between the typing events sent while the user types
+	typingThrottle = 3 * time.Second
+	// Time after their last typing event that someone stops being shown as
+	// typing
+	typingTimeout = 5 * time.Second
+)
+
+// Tells the others in the active chat that the user is typing, at most once
+// every typingThrottle
+func (m *chatModel) sendTyping() tea.Cmd {
+	draft := m.input.Value()
+	if m.chatStream == nil || strings.TrimSpace(draft) == "" || isCommand(draft) || time.Since(m.typingSent) < typingThrottle {
+		return nil
+	}
+	m.typingSent = time.Now()
+	return m.send(&pb.MessageStream{ChatId: m.activeChat, Typing: &pb.Typing{User: m.user}})
+}
+
+// Shows the sender of a typing event as typing until typingTimeout passes
+// without another
+func (m *chatModel) receiveTyping(t *pb.Typing) tea.Cmd {
+	if t.User == nil || t.User.Username == m.user.Username {
+		return nil
+	}
+	m.typing[t.User.Username] = time.Now()
+	return tea.Tick(typingTimeout, func(time.Time) tea.Msg {
+		return statusMsg{sType: STATUS_TYPING_EXPIRE}
+	})
+}
+
+// Stops showing the people whose last typing event is too old
+func (m *chatModel) expireTyping() {
+	for username, at := range m.typing {
+		if time.Since(at) >= typingTimeout {
+			delete(m.typing, username)
+		}
+	}
+}
+
+// Renders who is typing in the active chat i.e. alice is typing…
+func (m chatModel) formatTyping(width int) string {
+	names := make([]string, 0, len(m.typing))
+	for username := range m.typing {
+		names = append(names, username)
+	}
+	sort.Strings(names)
+
+	var line string
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		line = names[0] + " is typing…"
+	case 2:
+		line = names[0] + " and " + names[1] + " are typing…"
+	default:
+		line = fmt.Sprintf("%d people are typing…", len(names))
+	}
+	return suggestionDescStyle.Render(runewidth.Truncate(line, width, "…"))
+}
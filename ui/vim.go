@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Checks if keys run vim normal mode commands instead of being typed into
+// the focused input
+func (m chatModel) normalMode() bool {
+	return m.cfg.InputMode == "vim" && !m.insertMode
+}
+
+// Checks if the panel is typed into
+func isInputPanel(panel int) bool {
+	return panel == MESSAGE_PANEL || panel == SEND_REQUEST_PANNEL || panel == JOIN_ROOM_PANEL
+}
+
+// Handles the keys of vim normal mode. Keys it does not handle are left to
+// the panel focus logic.
+func (m *chatModel) updateNormalMode(msg tea.KeyMsg) (tea.Cmd, bool) {
+	pendingTop := m.pendingTop
+	m.pendingTop = false
+
+	switch {
+	case key.Matches(msg, m.keys.Insert):
+		if !isInputPanel(m.focusedPanel) {
+			m.focusedPanel = MESSAGE_PANEL
+			m.renderMessages()
+		}
+		m.insertMode = true
+		m.updateFocus()
+		return nil, true
+	case key.Matches(msg, m.keys.NormalDown):
+		m.moveSelection(false)
+		return nil, true
+	case key.Matches(msg, m.keys.NormalUp):
+		m.moveSelection(true)
+		return nil, true
+	case key.Matches(msg, m.keys.Top):
+		if pendingTop {
+			m.jumpSelection(true)
+		} else {
+			m.pendingTop = true
+		}
+		return nil, true
+	case key.Matches(msg, m.keys.Bottom):
+		m.jumpSelection(false)
+		return nil, true
+	}
+	return nil, false
+}
+
+// Moves the selection of the focused list, or of the chat view, to the first
+// or the last item
+func (m *chatModel) jumpSelection(top bool) {
+	switch m.focusedPanel {
+	case MESSSAGE_VIEW_PANEL:
+		if top {
+			m.selectedMsg = 0
+		} else {
+			m.selectedMsg = max(0, len(m.activeMessages())-1)
+		}
+		m.showReactors = false
+		m.renderMessages()
+	case CHATS_PANEL:
+		if top {
+			m.chatList.Select(0)
+		} else {
+			m.chatList.Select(max(0, len(m.chatList.VisibleItems())-1))
+		}
+	case ACTIVE_REQUEST_PANEL:
+		if top {
+			m.requestsList.Select(0)
+		} else {
+			m.requestsList.Select(max(0, len(m.requestsList.Items())-1))
+		}
+	}
+}
+
+// Labels the current mode for the status line
+func (m chatModel) formatMode() string {
+	if m.cfg.InputMode != "vim" {
+		return ""
+	}
+	if m.insertMode {
+		return modeStyle.Render("INSERT") + " "
+	}
+	return modeStyle.Render("NORMAL") + " "
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ayobami0/cli-chat/pb"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Returns a model in vim normal mode with three chats, the first of them
+// open with three messages
+func vimTestModel(panel int) chatModel {
+	now := time.Now()
+	m := newTestChatModel(
+		chatItem{id: "a", name: "a", chatType: pb.ChatType_CHAT_TYPE_GROUP, messages: []*pb.Message{
+			testMessage("1", "alice", now.Add(-3*time.Minute)),
+			testMessage("2", "alice", now.Add(-2*time.Minute)),
+			testMessage("3", "alice", now.Add(-time.Minute)),
+		}},
+		chatItem{id: "b", name: "b", chatType: pb.ChatType_CHAT_TYPE_GROUP},
+		chatItem{id: "c", name: "c", chatType: pb.ChatType_CHAT_TYPE_GROUP},
+	)
+	m.cfg.InputMode = "vim"
+	m.keys = newKeyMap(m.cfg)
+	m.activeChat = "a"
+	m.focusedPanel = panel
+	m.updateFocus()
+	return m
+}
+
+// Presses each key in turn
+func pressKeys(m chatModel, keys ...string) chatModel {
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		if k == "esc" {
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		model, _ := m.Update(msg)
+		m = model.(chatModel)
+	}
+	return m
+}
+
+func TestVimMovesSelection(t *testing.T) {
+	panels := []struct {
+		name     string
+		panel    int
+		selected func(m chatModel) int
+	}{
+		{"messages", MESSSAGE_VIEW_PANEL, func(m chatModel) int { return m.selectedMsg }},
+		{"chats", CHATS_PANEL, func(m chatModel) int { return m.chatList.Index() }},
+	}
+	tests := []struct {
+		keys []string
+		want int
+	}{
+		{[]string{"j"}, 1},
+		{[]string{"j", "j", "k"}, 1},
+		{[]string{"G"}, 2},
+		{[]string{"G", "g", "g"}, 0},
+		// g must be pressed twice in a row to go to the top
+		{[]string{"G", "g", "k", "g"}, 1},
+	}
+	for _, p := range panels {
+		for _, tt := range tests {
+			m := vimTestModel(p.panel)
+			m.selectedMsg = 0
+			m.chatList.Select(0)
+
+			if got := p.selected(pressKeys(m, tt.keys...)); got != tt.want {
+				t.Errorf("%s: %q selects %d, want %d", p.name, tt.keys, got, tt.want)
+			}
+		}
+	}
+
+	// The selected message stays on the last one, while the chat list wraps
+	m := pressKeys(vimTestModel(MESSSAGE_VIEW_PANEL), "G", "j")
+	if m.selectedMsg != 2 {
+		t.Errorf("j past the last message selects %d, want 2", m.selectedMsg)
+	}
+}
+
+func TestVimInsertMode(t *testing.T) {
+	m := pressKeys(vimTestModel(MESSSAGE_VIEW_PANEL), "j")
+	if m.input.Value() != "" {
+		t.Fatalf("%q was typed in normal mode", m.input.Value())
+	}
+
+	m = pressKeys(m, "i")
+	if !m.insertMode || m.focusedPanel != MESSAGE_PANEL || !m.input.Focused() {
+		t.Fatalf("i left insert mode %v, panel %d, input focused %v", m.insertMode, m.focusedPanel, m.input.Focused())
+	}
+	m = pressKeys(m, "j", "k")
+	if m.input.Value() != "jk" {
+		t.Errorf("input holds %q in insert mode, want jk", m.input.Value())
+	}
+
+	m = pressKeys(m, "esc")
+	if m.insertMode || m.input.Focused() {
+		t.Fatalf("esc left insert mode %v, input focused %v", m.insertMode, m.input.Focused())
+	}
+	m = pressKeys(m, "i", "G")
+	if m.input.Value() != "jkG" {
+		t.Errorf("input holds %q after returning to insert mode, want jkG", m.input.Value())
+	}
+}